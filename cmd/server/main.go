@@ -13,8 +13,10 @@ import (
 	"airbnb-clone/internal/config"
 	"airbnb-clone/internal/database"
 	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/notification"
 	"airbnb-clone/internal/repository"
 	"airbnb-clone/internal/service"
+	"airbnb-clone/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -56,19 +58,71 @@ func main() {
 	propertyRepo := repository.NewPropertyRepository(db)
 	bookingRepo := repository.NewBookingRepository(db)
 	reviewRepo := repository.NewReviewRepository(db)
+	taxRuleRepo := repository.NewTaxRuleRepository(db)
+	savedSearchRepo := repository.NewSavedSearchRepository(db)
+	wishlistRepo := repository.NewWishlistRepository(db)
+	amenityRepo := repository.NewAmenityRepository(db)
+	propertyImageRepo := repository.NewPropertyImageRepository(db)
+	moderationRepo := repository.NewModerationRepository(db)
+	propertyRevisionRepo := repository.NewPropertyRevisionRepository(db)
+	propertyTranslationRepo := repository.NewPropertyTranslationRepository(db)
+	cohostRepo := repository.NewCoHostRepository(db)
+	hostAnalyticsRepo := repository.NewHostAnalyticsRepository(db)
+
+	objectStore, err := storage.New(cfg.Storage)
+	if err != nil {
+		logger.Fatalf("Failed to set up file storage: %v", err)
+	}
+
+	notifier, err := notification.New(cfg.Notify)
+	if err != nil {
+		logger.Fatalf("Failed to set up notifications: %v", err)
+	}
+	if !notifier.Delivers() {
+		logger.Warn("Notifications are only logged, co-host invitations cannot be sent")
+	}
+
+	caches := service.NewCaches(cache.New(redisClient), cfg.Cache)
 
 	// Initialize services
-	userService := service.NewUserService(userRepo, cfg.JWT)
-	propertyService := service.NewPropertyService(propertyRepo, redisClient)
-	bookingService := service.NewBookingService(bookingRepo, propertyRepo)
-	reviewService := service.NewReviewService(reviewRepo, bookingRepo)
+	userService := service.NewUserService(userRepo, cfg.JWT, redisClient, caches)
+	amenityService := service.NewAmenityService(amenityRepo, caches)
+	cohostService := service.NewCoHostService(cohostRepo, propertyRepo, userRepo, notifier)
+	propertyRevisionService := service.NewPropertyRevisionService(propertyRevisionRepo, propertyRepo, cohostService)
+	propertyImageService := service.NewPropertyImageService(propertyImageRepo, propertyRepo, cohostService, objectStore, caches, int64(cfg.Storage.MaxImageMB)<<20)
+	propertyTranslationService := service.NewPropertyTranslationService(propertyTranslationRepo, propertyRepo, cohostService, caches)
+	propertyService := service.NewPropertyService(propertyRepo, amenityService, propertyImageService, propertyRevisionService, cohostService, caches)
+	bookingService := service.NewBookingService(bookingRepo, propertyRepo, taxRuleRepo, propertyRevisionService, cohostService, caches)
+	reviewService := service.NewReviewService(reviewRepo, bookingRepo, propertyRepo, caches)
+	taxRuleService := service.NewTaxRuleService(taxRuleRepo)
+	savedSearchService := service.NewSavedSearchService(savedSearchRepo, propertyRepo, amenityService, notifier)
+	moderationService := service.NewModerationService(moderationRepo, propertyRepo, propertyImageService, cohostService, notifier, caches)
+	hostAnalyticsService := service.NewHostAnalyticsService(hostAnalyticsRepo, propertyRepo, cohostService)
+	wishlistService := service.NewWishlistService(wishlistRepo, propertyRepo, userRepo)
+	recentlyViewedService := service.NewRecentlyViewedService(redisClient, propertyRepo, userService)
+
+	// Start background jobs, stopped on shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go savedSearchService.StartMatcher(jobsCtx, time.Duration(cfg.Alerts.SavedSearchIntervalMinutes)*time.Minute)
 
 	// Initialize router
 	router := api.NewRouter(api.Services{
-		UserService:     userService,
-		PropertyService: propertyService,
-		BookingService:  bookingService,
-		ReviewService:   reviewService,
+		UserService:                userService,
+		PropertyService:            propertyService,
+		BookingService:             bookingService,
+		ReviewService:              reviewService,
+		TaxRuleService:             taxRuleService,
+		SavedSearchService:         savedSearchService,
+		WishlistService:            wishlistService,
+		RecentlyViewedService:      recentlyViewedService,
+		AmenityService:             amenityService,
+		PropertyImageService:       propertyImageService,
+		ModerationService:          moderationService,
+		PropertyRevisionService:    propertyRevisionService,
+		PropertyTranslationService: propertyTranslationService,
+		CoHostService:              cohostService,
+		HostAnalyticsService:       hostAnalyticsService,
 	}, cfg, redisClient)
 
 	if cfg.Server.Environment == "production" {
@@ -95,6 +149,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("Shutting down server...")
+	stopJobs()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
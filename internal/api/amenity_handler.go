@@ -0,0 +1,182 @@
+package api
+
+import (
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+type AmenityHandler struct {
+	amenityService *service.AmenityService
+}
+
+func NewAmenityHandler(amenityService *service.AmenityService) *AmenityHandler {
+	return &AmenityHandler{
+		amenityService: amenityService,
+	}
+}
+
+// returns the languages the response is wanted in, most wanted first: the
+// lang query parameter, or else the Accept-Language header by q-value. The
+// response varies with the header, so caches are told.
+func requestLanguages(c *gin.Context) []language.Tag {
+	c.Writer.Header().Add("Vary", "Accept-Language")
+
+	if lang := strings.TrimSpace(c.Query("lang")); lang != "" {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil
+		}
+		return []language.Tag{tag}
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if err != nil {
+		return nil
+	}
+	return tags
+}
+
+// returns the locale the names of the amenities are best shown in for the
+// request
+func amenityLocale(c *gin.Context, amenities ...*models.Amenity) string {
+	var available []string
+	seen := make(map[string]bool)
+	for _, amenity := range amenities {
+		for locale := range amenity.Names {
+			if !seen[locale] {
+				seen[locale] = true
+				available = append(available, locale)
+			}
+		}
+	}
+	// map order is random, the matcher prefers earlier locales on ties
+	sort.Strings(available)
+
+	return models.MatchLocale(requestLanguages(c), available)
+}
+
+func (h *AmenityHandler) ListAmenities(c *gin.Context) {
+	amenities, err := h.amenityService.ListAmenities(c.Query("category"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	locale := amenityLocale(c, amenities...)
+	responses := make([]*models.AmenityResponse, len(amenities))
+	for i, amenity := range amenities {
+		responses[i] = amenity.ToResponse(locale)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"amenities": responses})
+}
+
+func (h *AmenityHandler) GetAmenity(c *gin.Context) {
+	amenity, err := h.amenityService.GetAmenity(c.Param("id"))
+	if err != nil {
+		if err.Error() == "amenity not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, amenity.ToResponse(amenityLocale(c, amenity)))
+}
+
+func (h *AmenityHandler) CreateAmenity(c *gin.Context) {
+	var req models.AmenityCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	amenity, err := h.amenityService.CreateAmenity(&req)
+	if err != nil {
+		if err.Error() == "amenity already exists" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if isAmenityValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, amenity)
+}
+
+func (h *AmenityHandler) UpdateAmenity(c *gin.Context) {
+	var req models.AmenityUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	amenity, err := h.amenityService.UpdateAmenity(c.Param("id"), &req)
+	if err != nil {
+		if err.Error() == "amenity not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if isAmenityValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, amenity)
+}
+
+func (h *AmenityHandler) DeleteAmenity(c *gin.Context) {
+	err := h.amenityService.DeleteAmenity(c.Param("id"))
+	if err != nil {
+		switch err.Error() {
+		case "amenity not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "amenity is used by properties":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Amenity deleted successfully"})
+}
+
+// GetUnmappedAmenities lists the listing amenities an admin still has to add
+// to the catalog, as an amenity or an alias
+func (h *AmenityHandler) GetUnmappedAmenities(c *gin.Context) {
+	unmapped, err := h.amenityService.GetUnmappedAmenities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unmapped_amenities": unmapped})
+}
+
+func isAmenityValidationError(err error) bool {
+	switch err.Error() {
+	case "amenity id is required",
+		"invalid amenity id",
+		"amenity is a house rule or accessibility feature",
+		"amenity category is required",
+		"amenity needs an English name",
+		"amenity alias is used by another amenity":
+		return true
+	}
+	return false
+}
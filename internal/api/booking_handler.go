@@ -3,7 +3,9 @@ package api
 import (
 	"airbnb-clone/internal/middleware"
 	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/receipt"
 	"airbnb-clone/internal/service"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -91,6 +93,65 @@ func (h *BookingHandler) GetBooking(c *gin.Context) {
 	c.JSON(http.StatusOK, booking)
 }
 
+func (h *BookingHandler) GetBookingReceipt(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	userRole, err := middleware.GetUserRole(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookingIDStr := c.Param("id")
+	bookingID, err := uuid.Parse(bookingIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid booking ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "html")
+	if format != "html" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format. Use html or pdf"})
+		return
+	}
+
+	bookingReceipt, err := h.bookingService.GetBookingReceipt(bookingID, userID, userRole)
+	if err != nil {
+		if err.Error() == "booking not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "unauthorized: you can only view your own bookings" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "pdf" {
+		body, err := receipt.RenderPDF(bookingReceipt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, bookingReceipt.ReceiptNumber))
+		c.Data(http.StatusOK, "application/pdf", body)
+		return
+	}
+
+	body, err := receipt.RenderHTML(bookingReceipt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", body)
+}
+
 func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
@@ -192,17 +253,23 @@ func (h *BookingHandler) GetMyBookings(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
 
-	bookings, err := h.bookingService.GetUserBookings(userID, page, limit)
+	bookings, nextCursor, err := h.bookingService.GetUserBookings(userID, page, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"bookings": bookings,
-		"page":     page,
-		"limit":    limit,
+		"bookings":    bookings,
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
 
@@ -222,9 +289,14 @@ func (h *BookingHandler) GetPropertyBookings(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
 
-	bookings, err := h.bookingService.GetPropertyBookings(propertyID, userID, page, limit)
+	bookings, nextCursor, err := h.bookingService.GetPropertyBookings(propertyID, userID, page, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err.Error() == "property not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -242,6 +314,7 @@ func (h *BookingHandler) GetPropertyBookings(c *gin.Context) {
 		"property_id": propertyID,
 		"page":        page,
 		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
 
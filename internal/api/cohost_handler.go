@@ -0,0 +1,238 @@
+package api
+
+import (
+	"airbnb-clone/internal/middleware"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CoHostHandler struct {
+	cohostService *service.CoHostService
+}
+
+func NewCoHostHandler(cohostService *service.CoHostService) *CoHostHandler {
+	return &CoHostHandler{
+		cohostService: cohostService,
+	}
+}
+
+// GetCoHosts lists the co-hosts of a property to its host, its co-hosts or
+// an admin
+func (h *CoHostHandler) GetCoHosts(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	cohosts, err := h.cohostService.GetCoHosts(propertyID, userID, role == string(models.UserRoleAdmin))
+	if err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cohosts": cohosts})
+}
+
+// InviteCoHost emails an invitation to help manage the property
+func (h *CoHostHandler) InviteCoHost(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	var req models.CoHostInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.cohostService.InviteCoHost(propertyID, userID, &req)
+	if err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitations lists the invitations of a property that were not accepted
+// yet
+func (h *CoHostHandler) GetInvitations(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	invitations, err := h.cohostService.GetInvitations(propertyID, userID, role == string(models.UserRoleAdmin))
+	if err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+func (h *CoHostHandler) RevokeInvitation(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	invitationID, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	if err := h.cohostService.RevokeInvitation(propertyID, invitationID, userID); err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitation makes the signed in user a co-host with the token from
+// their invitation email
+func (h *CoHostHandler) AcceptInvitation(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CoHostAcceptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cohost, err := h.cohostService.AcceptInvitation(userID, &req)
+	if err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohost)
+}
+
+// UpdateCoHost replaces the permissions of a co-host
+func (h *CoHostHandler) UpdateCoHost(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	cohostID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.CoHostUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cohost, err := h.cohostService.UpdateCoHost(propertyID, cohostID, userID, &req)
+	if err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cohost)
+}
+
+// RemoveCoHost removes a co-host, or lets a co-host leave the property
+func (h *CoHostHandler) RemoveCoHost(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	cohostID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.cohostService.RemoveCoHost(propertyID, cohostID, userID); err != nil {
+		respondCoHostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Co-host removed successfully"})
+}
+
+func respondCoHostError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "property not found", "invitation not found", "co-host not found", "user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "unauthorized: only the host can manage co-hosts",
+		"unauthorized: you can only view your own properties",
+		"this invitation was sent to another email address":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "user is already a co-host", "invitation has already been accepted":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "invitation has expired":
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case "invitations cannot be sent, email delivery is not configured":
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case "invalid email",
+		"invalid co-host permission",
+		"co-hosts need at least one permission",
+		"the host cannot be a co-host":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"airbnb-clone/internal/middleware"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HostAnalyticsHandler struct {
+	analyticsService *service.HostAnalyticsService
+}
+
+func NewHostAnalyticsHandler(analyticsService *service.HostAnalyticsService) *HostAnalyticsHandler {
+	return &HostAnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetMyAnalytics returns occupancy, revenue, bookings and ratings of the
+// properties of the signed in host between from and to, both inclusive
+func (h *HostAnalyticsHandler) GetMyAnalytics(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.HostAnalyticsRequest
+	if from := c.Query("from"); from != "" {
+		req.From, err = time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		req.To, err = time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+	if value := c.Query("property_id"); value != "" {
+		propertyID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+			return
+		}
+		req.PropertyID = &propertyID
+	}
+
+	analytics, err := h.analyticsService.GetAnalytics(userID, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "property not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err.Error() == "unauthorized: you can only view analytics for your own properties":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "from must not be after to",
+			strings.HasPrefix(err.Error(), "the period cannot be longer than"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
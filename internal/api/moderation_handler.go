@@ -0,0 +1,184 @@
+package api
+
+import (
+	"airbnb-clone/internal/middleware"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// GetQueue lists the listings and changes waiting for approval. Filters are
+// q, city, country, type, host_id, changes and resubmitted.
+func (h *ModerationHandler) GetQueue(c *gin.Context) {
+	var req models.ModerationQueueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if hostID := c.Query("host_id"); hostID != "" {
+		id, err := uuid.Parse(hostID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid host_id"})
+			return
+		}
+		req.HostID = &id
+	}
+
+	if changes := c.Query("changes"); changes != "" {
+		value, err := strconv.ParseBool(changes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid changes value"})
+			return
+		}
+		req.Changes = &value
+	}
+
+	if resubmitted := c.Query("resubmitted"); resubmitted != "" {
+		value, err := strconv.ParseBool(resubmitted)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resubmitted value"})
+			return
+		}
+		req.Resubmitted = &value
+	}
+
+	queue, err := h.moderationService.GetQueue(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+func (h *ModerationHandler) ListRejectionReasons(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reasons": models.RejectionReasons})
+}
+
+// ApproveProperty takes optional notes in the body
+func (h *ModerationHandler) ApproveProperty(c *gin.Context) {
+	reviewerID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	var req models.PropertyApproveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	property, err := h.moderationService.ApproveProperty(propertyID, reviewerID, &req)
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, property)
+}
+
+func (h *ModerationHandler) RejectProperty(c *gin.Context) {
+	reviewerID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	var req models.PropertyRejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	property, err := h.moderationService.RejectProperty(propertyID, reviewerID, &req)
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, property)
+}
+
+// GetDecisions returns the moderation history of a listing to its host or an
+// admin
+func (h *ModerationHandler) GetDecisions(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	decisions, err := h.moderationService.GetDecisions(propertyID, userID, role == string(models.UserRoleAdmin))
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"decisions": decisions})
+}
+
+func respondModerationError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "property not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "unauthorized: you can only view your own properties":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "only pending properties can be moderated",
+		err.Error() == "the listing changed since it was reviewed":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case isModerationValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func isModerationValidationError(err error) bool {
+	if service.IsUnknownRejectionReasonError(err) {
+		return true
+	}
+	switch err.Error() {
+	case "at least one rejection reason is required",
+		"notes are required when the reason is other",
+		"moderation notes are too long":
+		return true
+	}
+	return false
+}
@@ -1,11 +1,16 @@
 package api
 
 import (
+	"airbnb-clone/internal/logger"
 	"airbnb-clone/internal/middleware"
 	"airbnb-clone/internal/models"
 	"airbnb-clone/internal/service"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,15 +18,50 @@ import (
 )
 
 type PropertyHandler struct {
-	propertyService *service.PropertyService
+	propertyService       *service.PropertyService
+	wishlistService       *service.WishlistService
+	recentlyViewedService *service.RecentlyViewedService
+	translationService    *service.PropertyTranslationService
 }
 
-func NewPropertyHandler(propertyService *service.PropertyService) *PropertyHandler {
+func NewPropertyHandler(propertyService *service.PropertyService, wishlistService *service.WishlistService, recentlyViewedService *service.RecentlyViewedService, translationService *service.PropertyTranslationService) *PropertyHandler {
 	return &PropertyHandler{
-		propertyService: propertyService,
+		propertyService:       propertyService,
+		wishlistService:       wishlistService,
+		recentlyViewedService: recentlyViewedService,
+		translationService:    translationService,
 	}
 }
 
+// shows the properties in the language of the request where they were
+// translated. Lookup failures get the original text.
+func (h *PropertyHandler) localize(c *gin.Context, properties []*models.PropertyResponse) []*models.PropertyResponse {
+	localized, err := h.translationService.Localize(properties, requestLanguages(c))
+	if err != nil {
+		logger.Errorf("failed to localize properties: %v", err)
+		return properties
+	}
+
+	return localized
+}
+
+// sets is_favorited on the properties when the request is authenticated.
+// Anonymous requests and lookup failures get the properties unmarked.
+func (h *PropertyHandler) markFavorites(c *gin.Context, properties []*models.PropertyResponse) []*models.PropertyResponse {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return properties
+	}
+
+	marked, err := h.wishlistService.MarkFavorites(userID, properties)
+	if err != nil {
+		logger.Errorf("failed to mark favorited properties: %v", err)
+		return properties
+	}
+
+	return marked
+}
+
 func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
@@ -37,6 +77,10 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 
 	property, err := h.propertyService.CreateProperty(userID, &req)
 	if err != nil {
+		if isPropertyValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -62,7 +106,14 @@ func (h *PropertyHandler) GetProperty(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, property)
+	// a failure to record the view never fails the page
+	if userID, err := middleware.GetUserID(c); err == nil {
+		if err := h.recentlyViewedService.RecordView(userID, propertyID); err != nil {
+			logger.Errorf("failed to record property view: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, h.markFavorites(c, h.localize(c, []*models.PropertyResponse{property}))[0])
 }
 
 func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
@@ -95,6 +146,15 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if err.Error() == "only approved listings can be activated or deactivated" ||
+			err.Error() == "the listing changed meanwhile, reload it and try again" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if isPropertyValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -136,28 +196,36 @@ func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
 func (h *PropertyHandler) ListProperties(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
 
-	properties, err := h.propertyService.GetProperties(page, limit)
+	properties, nextCursor, err := h.propertyService.GetProperties(page, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"properties": properties,
-		"page":       page,
-		"limit":      limit,
+		"properties":  h.markFavorites(c, h.localize(c, properties)),
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
 
 func (h *PropertyHandler) SearchProperties(c *gin.Context) {
 	var req models.PropertySearchRequest
 
+	req.Query = strings.TrimSpace(c.Query("q"))
 	req.City = c.Query("city")
 	req.State = c.Query("state")
 	req.Country = c.Query("country")
 	req.Type = c.Query("type")
 	req.Amenities = c.QueryArray("amenities")
+	req.Accessibility = c.QueryArray("accessibility")
 
 	if checkIn := c.Query("check_in"); checkIn != "" {
 		parsed, err := time.Parse("2006-01-02", checkIn)
@@ -186,6 +254,45 @@ func (h *PropertyHandler) SearchProperties(c *gin.Context) {
 		req.Guests = num
 	}
 
+	if minBedrooms := c.Query("min_bedrooms"); minBedrooms != "" {
+		num, err := strconv.Atoi(minBedrooms)
+		if err != nil || num < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_bedrooms value"})
+			return
+		}
+		req.MinBedrooms = num
+	}
+
+	if minBeds := c.Query("min_beds"); minBeds != "" {
+		num, err := strconv.Atoi(minBeds)
+		if err != nil || num < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_beds value"})
+			return
+		}
+		req.MinBeds = num
+	}
+
+	houseRules := []struct {
+		param string
+		value **bool
+	}{
+		{"pets_allowed", &req.PetsAllowed},
+		{"smoking_allowed", &req.SmokingAllowed},
+		{"events_allowed", &req.EventsAllowed},
+		{"children_allowed", &req.ChildrenAllowed},
+		{"infants_allowed", &req.InfantsAllowed},
+	}
+	for _, rule := range houseRules {
+		if value := c.Query(rule.param); value != "" {
+			allowed, err := strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + rule.param + " value"})
+				return
+			}
+			*rule.value = &allowed
+		}
+	}
+
 	if minPrice := c.Query("min_price"); minPrice != "" {
 		price, err := strconv.ParseFloat(minPrice, 64)
 		if err != nil {
@@ -204,16 +311,157 @@ func (h *PropertyHandler) SearchProperties(c *gin.Context) {
 		req.MaxPrice = price
 	}
 
+	if lat := c.Query("lat"); lat != "" {
+		value, err := strconv.ParseFloat(lat, 64)
+		if err != nil || value < -90 || value > 90 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lat"})
+			return
+		}
+		req.Lat = &value
+	}
+
+	if lng := c.Query("lng"); lng != "" {
+		value, err := strconv.ParseFloat(lng, 64)
+		if err != nil || value < -180 || value > 180 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lng"})
+			return
+		}
+		req.Lng = &value
+	}
+
+	if (req.Lat == nil) != (req.Lng == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be provided together"})
+		return
+	}
+
+	if radius := c.Query("radius_km"); radius != "" {
+		value, err := strconv.ParseFloat(radius, 64)
+		if err != nil || value <= 0 || value > models.MaxSearchRadiusKm {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid radius_km. Must be between 0 and %d", models.MaxSearchRadiusKm)})
+			return
+		}
+		if req.Lat == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius_km requires lat and lng"})
+			return
+		}
+		req.RadiusKm = value
+	}
+
+	if bbox := c.Query("bbox"); bbox != "" {
+		parsed, err := parseBoundingBox(bbox)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.BBox = parsed
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		if !models.IsValidSearchSort(sort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort. Use price_asc, price_desc, rating, newest, distance or relevance"})
+			return
+		}
+		if sort == models.SearchSortDistance && req.Lat == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sort by distance requires lat and lng"})
+			return
+		}
+		if sort == models.SearchSortRelevance && req.Query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sort by relevance requires q"})
+			return
+		}
+		req.Sort = sort
+	}
+
 	req.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
 	req.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
 
+	// q also matches listings translated to the language of the request
+	req.Locale = searchLocale(c)
+
 	response, err := h.propertyService.SearchProperties(&req)
 	if err != nil {
+		if service.IsUnknownAmenitiesError(err) || service.IsUnknownAccessibilityFeatureError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// the response may be a shared cache entry, so mark a copy
+	marked := *response
+	marked.Properties = h.markFavorites(c, h.localize(c, response.Properties))
+
+	c.JSON(http.StatusOK, marked)
+}
+
+func (h *PropertyHandler) SuggestDestinations(c *gin.Context) {
+	query := c.Query("q")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	suggestions, err := h.propertyService.SuggestDestinations(query, limit)
+	if err != nil {
+		if err.Error() == "search query is required" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, gin.H{
+		"query":        query,
+		"destinations": suggestions,
+	})
+}
+
+func (h *PropertyHandler) GetSimilarProperties(c *gin.Context) {
+	propertyIDStr := c.Param("id")
+	propertyID, err := uuid.Parse(propertyIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	var checkIn, checkOut time.Time
+	if value := c.Query("check_in"); value != "" {
+		checkIn, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid check_in format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+	if value := c.Query("check_out"); value != "" {
+		checkOut, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid check_out format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+	if checkIn.IsZero() != checkOut.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "check_in and check_out must be provided together"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	properties, err := h.propertyService.GetSimilarProperties(propertyID, checkIn, checkOut, limit)
+	if err != nil {
+		switch err.Error() {
+		case "property not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "check-out date must be after check-in date":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"property_id": propertyID,
+		"properties":  h.markFavorites(c, h.localize(c, properties)),
+	})
 }
 
 func (h *PropertyHandler) GetMyProperties(c *gin.Context) {
@@ -225,17 +473,23 @@ func (h *PropertyHandler) GetMyProperties(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
 
-	properties, err := h.propertyService.GetPropertiesByHost(userID, page, limit)
+	properties, nextCursor, err := h.propertyService.GetPropertiesByHost(userID, page, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"properties": properties,
-		"page":       page,
-		"limit":      limit,
+		"properties":  properties,
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
 
@@ -273,23 +527,271 @@ func (h *PropertyHandler) CheckAvailability(c *gin.Context) {
 	})
 }
 
-func (h *PropertyHandler) ApproveProperty(c *gin.Context) {
-	propertyIDStr := c.Param("id")
-	propertyID, err := uuid.Parse(propertyIDStr)
+// parses a bbox query value in the form min_lng,min_lat,max_lng,max_lat
+func parseBoundingBox(value string) (*models.BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, errors.New("invalid bbox. Use min_lng,min_lat,max_lng,max_lat")
+	}
+
+	coords := make([]float64, 4)
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, errors.New("invalid bbox. Use min_lng,min_lat,max_lng,max_lat")
+		}
+		coords[i] = coord
+	}
+
+	bbox := &models.BoundingBox{MinLng: coords[0], MinLat: coords[1], MaxLng: coords[2], MaxLat: coords[3]}
+	if err := bbox.Validate(); err != nil {
+		return nil, err
+	}
+
+	return bbox, nil
+}
+
+func isPropertyValidationError(err error) bool {
+	if service.IsUnknownAmenitiesError(err) || service.IsUnknownAccessibilityFeatureError(err) {
+		return true
+	}
+	switch err.Error() {
+	case "property has too many images",
+		"hosts can only set the status to active or inactive",
+		"property has too many rooms",
+		"invalid room type",
+		"room name is too long",
+		"invalid bed type",
+		"invalid bed size",
+		"bed size is required for beds and sofa beds",
+		"bed count must be between 1 and 10",
+		"quiet hours need both a start and an end",
+		"invalid quiet hours. Use HH:MM",
+		"quiet hours must start and end at different times":
+		return true
+	}
+	return false
+}
+
+// CreateDraft starts a listing that the host fills in step by step. The body
+// is optional and takes the fields of the basics step.
+func (h *PropertyHandler) CreateDraft(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.DraftBasicsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	draft, err := h.propertyService.CreateDraft(userID, &req)
+	if err != nil {
+		respondDraftError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, draft)
+}
+
+func (h *PropertyHandler) GetDraft(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
 		return
 	}
 
-	property, err := h.propertyService.ApproveProperty(propertyID)
+	draft, err := h.propertyService.GetDraft(propertyID, userID)
 	if err != nil {
-		if err.Error() == "property not found" {
+		respondDraftError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+// binds the body of a draft step into req and applies it with update
+func (h *PropertyHandler) updateDraftStep(c *gin.Context, req interface{}, update func(propertyID, userID uuid.UUID) (*models.PropertyDraftResponse, error)) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	draft, err := update(propertyID, userID)
+	if err != nil {
+		respondDraftError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+func (h *PropertyHandler) UpdateDraftBasics(c *gin.Context) {
+	var req models.DraftBasicsRequest
+	h.updateDraftStep(c, &req, func(propertyID, userID uuid.UUID) (*models.PropertyDraftResponse, error) {
+		return h.propertyService.UpdateDraftBasics(propertyID, userID, &req)
+	})
+}
+
+func (h *PropertyHandler) UpdateDraftLocation(c *gin.Context) {
+	var req models.DraftLocationRequest
+	h.updateDraftStep(c, &req, func(propertyID, userID uuid.UUID) (*models.PropertyDraftResponse, error) {
+		return h.propertyService.UpdateDraftLocation(propertyID, userID, &req)
+	})
+}
+
+func (h *PropertyHandler) UpdateDraftCapacity(c *gin.Context) {
+	var req models.DraftCapacityRequest
+	h.updateDraftStep(c, &req, func(propertyID, userID uuid.UUID) (*models.PropertyDraftResponse, error) {
+		return h.propertyService.UpdateDraftCapacity(propertyID, userID, &req)
+	})
+}
+
+func (h *PropertyHandler) UpdateDraftAmenities(c *gin.Context) {
+	var req models.DraftAmenitiesRequest
+	h.updateDraftStep(c, &req, func(propertyID, userID uuid.UUID) (*models.PropertyDraftResponse, error) {
+		return h.propertyService.UpdateDraftAmenities(propertyID, userID, &req)
+	})
+}
+
+func (h *PropertyHandler) UpdateDraftPricing(c *gin.Context) {
+	var req models.DraftPricingRequest
+	h.updateDraftStep(c, &req, func(propertyID, userID uuid.UUID) (*models.PropertyDraftResponse, error) {
+		return h.propertyService.UpdateDraftPricing(propertyID, userID, &req)
+	})
+}
+
+func (h *PropertyHandler) UpdateDraftRules(c *gin.Context) {
+	var req models.DraftRulesRequest
+	h.updateDraftStep(c, &req, func(propertyID, userID uuid.UUID) (*models.PropertyDraftResponse, error) {
+		return h.propertyService.UpdateDraftRules(propertyID, userID, &req)
+	})
+}
+
+// SubmitProperty sends a complete draft for approval
+func (h *PropertyHandler) SubmitProperty(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	property, err := h.propertyService.SubmitDraft(propertyID, userID)
+	if err != nil {
+		respondDraftError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, property)
+}
+
+// RollbackProperty restores a listing of the host to one of its revisions
+func (h *PropertyHandler) RollbackProperty(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	revisionID, err := uuid.Parse(c.Param("revision_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+
+	property, err := h.propertyService.RollbackProperty(propertyID, revisionID, userID)
+	if err != nil {
+		switch {
+		case err.Error() == "property not found", err.Error() == "revision not found":
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
+		case err.Error() == "unauthorized: you can only update your own properties":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "the listing changed meanwhile, reload it and try again":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case isPropertyValidationError(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, property)
-}
\ No newline at end of file
+}
+
+func respondDraftError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "property not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "unauthorized: you can only update your own properties":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "only drafts and rejected listings can be edited step by step",
+		err.Error() == "only drafts and rejected listings can be submitted",
+		err.Error() == "the listing changed meanwhile, reload it and try again":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.IsIncompleteListingError(err), isDraftValidationError(err), isPropertyValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func isDraftValidationError(err error) bool {
+	switch err.Error() {
+	case "title is too long",
+		"invalid property type",
+		"invalid latitude",
+		"invalid longitude",
+		"max guests must be between 1 and 20",
+		"bedrooms must be between 0 and 20",
+		"bathrooms must be between 1 and 20",
+		"price per night must be at least 1",
+		"invalid currency":
+		return true
+	}
+	return false
+}
+
+// the locale a text query of the request is written in, empty when the
+// request names no language
+func searchLocale(c *gin.Context) string {
+	if languages := requestLanguages(c); len(languages) > 0 {
+		if locale, ok := models.NormalizeLocale(languages[0].String()); ok {
+			return locale
+		}
+	}
+	return ""
+}
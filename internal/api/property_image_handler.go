@@ -0,0 +1,212 @@
+package api
+
+import (
+	"airbnb-clone/internal/middleware"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PropertyImageHandler struct {
+	imageService *service.PropertyImageService
+}
+
+func NewPropertyImageHandler(imageService *service.PropertyImageService) *PropertyImageHandler {
+	return &PropertyImageHandler{
+		imageService: imageService,
+	}
+}
+
+// UploadImage takes a multipart form with the file in "image" and an
+// optional "caption"
+func (h *PropertyImageHandler) UploadImage(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	// leave room for the rest of the form around the file
+	maxBytes := h.imageService.MaxImageBytes()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required"})
+		return
+	}
+	if fileHeader.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image"})
+		return
+	}
+
+	image, err := h.imageService.UploadImage(propertyID, userID, data, c.PostForm("caption"))
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, image)
+}
+
+func (h *PropertyImageHandler) GetPropertyImages(c *gin.Context) {
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	// hosts and admins also see the photos waiting for review
+	viewerID, _ := middleware.GetUserID(c)
+	role, _ := middleware.GetUserRole(c)
+
+	images, err := h.imageService.GetPropertyImages(propertyID, viewerID, role == string(models.UserRoleAdmin))
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+func (h *PropertyImageHandler) UpdateImage(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	imageID, err := uuid.Parse(c.Param("image_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	var req models.PropertyImageUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	image, err := h.imageService.UpdateImage(propertyID, imageID, userID, &req)
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, image)
+}
+
+func (h *PropertyImageHandler) ReorderImages(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	var req models.PropertyImageReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := h.imageService.ReorderImages(propertyID, userID, req.ImageIDs)
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+func (h *PropertyImageHandler) DeleteImage(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	imageID, err := uuid.Parse(c.Param("image_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	if err := h.imageService.DeleteImage(propertyID, imageID, userID); err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted successfully"})
+}
+
+func respondImageError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "property not found", "image not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "unauthorized: you can only update your own properties":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "image is too large":
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case "unsupported image type":
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": fmt.Sprintf("%s. Use JPEG, PNG or GIF", err.Error())})
+	case "invalid image",
+		"image dimensions are too large",
+		"image caption is too long",
+		"property has too many images",
+		"set another image as cover instead",
+		"image is waiting for review",
+		"image order must list every image of the property exactly once":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
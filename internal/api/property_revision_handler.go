@@ -0,0 +1,99 @@
+package api
+
+import (
+	"airbnb-clone/internal/middleware"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PropertyRevisionHandler struct {
+	revisionService *service.PropertyRevisionService
+}
+
+func NewPropertyRevisionHandler(revisionService *service.PropertyRevisionService) *PropertyRevisionHandler {
+	return &PropertyRevisionHandler{
+		revisionService: revisionService,
+	}
+}
+
+// GetRevisions lists the revisions of a listing to its host or an admin,
+// newest first
+func (h *PropertyRevisionHandler) GetRevisions(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
+
+	revisions, nextCursor, err := h.revisionService.GetRevisions(propertyID, userID, role == string(models.UserRoleAdmin), page, limit, cursor)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revisions":   revisions,
+		"property_id": propertyID,
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *PropertyRevisionHandler) GetRevision(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	revisionID, err := uuid.Parse(c.Param("revision_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+		return
+	}
+
+	revision, err := h.revisionService.GetRevision(propertyID, revisionID, userID, role == string(models.UserRoleAdmin))
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+func respondRevisionError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "property not found", "revision not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "unauthorized: you can only view your own properties":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "invalid cursor":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"airbnb-clone/internal/middleware"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PropertyTranslationHandler struct {
+	translationService *service.PropertyTranslationService
+}
+
+func NewPropertyTranslationHandler(translationService *service.PropertyTranslationService) *PropertyTranslationHandler {
+	return &PropertyTranslationHandler{
+		translationService: translationService,
+	}
+}
+
+// GetTranslations lists the translations of a listing to its host or an admin
+func (h *PropertyTranslationHandler) GetTranslations(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	translations, err := h.translationService.GetTranslations(propertyID, userID, role == string(models.UserRoleAdmin))
+	if err != nil {
+		respondTranslationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"translations": translations})
+}
+
+// SetTranslation adds or replaces the translation in the locale of the path
+func (h *PropertyTranslationHandler) SetTranslation(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	var req models.PropertyTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	translation, err := h.translationService.SetTranslation(propertyID, userID, c.Param("locale"), &req)
+	if err != nil {
+		respondTranslationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+func (h *PropertyTranslationHandler) DeleteTranslation(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	propertyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid property ID"})
+		return
+	}
+
+	if err := h.translationService.DeleteTranslation(propertyID, userID, c.Param("locale")); err != nil {
+		respondTranslationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Translation deleted successfully"})
+}
+
+func respondTranslationError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "property not found", "translation not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "unauthorized: you can only update your own properties":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "invalid locale",
+		"the original text is used for the default locale",
+		"title is too long",
+		"translation has too many rules",
+		"translation is empty":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -162,9 +162,14 @@ func (h *ReviewHandler) GetPropertyReviews(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
 
-	reviews, err := h.reviewService.GetPropertyReviews(propertyID, page, limit)
+	reviews, nextCursor, err := h.reviewService.GetPropertyReviews(propertyID, page, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -174,6 +179,7 @@ func (h *ReviewHandler) GetPropertyReviews(c *gin.Context) {
 		"property_id": propertyID,
 		"page":        page,
 		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
 
@@ -186,33 +192,45 @@ func (h *ReviewHandler) GetMyReviews(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
 
-	reviews, err := h.reviewService.GetUserReviews(userID, page, limit)
+	reviews, nextCursor, err := h.reviewService.GetUserReviews(userID, page, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"reviews": reviews,
-		"page":    page,
-		"limit":   limit,
+		"reviews":     reviews,
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
 
 func (h *ReviewHandler) GetAllReviews(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
 
-	reviews, err := h.reviewService.GetAllReviews(page, limit)
+	reviews, nextCursor, err := h.reviewService.GetAllReviews(page, limit, cursor)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"reviews": reviews,
-		"page":    page,
-		"limit":   limit,
+		"reviews":     reviews,
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
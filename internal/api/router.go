@@ -12,10 +12,21 @@ import (
 
 // holds all service dependencies
 type Services struct {
-	UserService     *service.UserService
-	PropertyService *service.PropertyService
-	BookingService  *service.BookingService
-	ReviewService   *service.ReviewService
+	UserService                *service.UserService
+	PropertyService            *service.PropertyService
+	BookingService             *service.BookingService
+	ReviewService              *service.ReviewService
+	TaxRuleService             *service.TaxRuleService
+	SavedSearchService         *service.SavedSearchService
+	WishlistService            *service.WishlistService
+	RecentlyViewedService      *service.RecentlyViewedService
+	AmenityService             *service.AmenityService
+	PropertyImageService       *service.PropertyImageService
+	ModerationService          *service.ModerationService
+	PropertyRevisionService    *service.PropertyRevisionService
+	PropertyTranslationService *service.PropertyTranslationService
+	CoHostService              *service.CoHostService
+	HostAnalyticsService       *service.HostAnalyticsService
 }
 
 // creates and configures the main router
@@ -45,22 +56,33 @@ func NewRouter(services Services, cfg *config.Config, redisClient *cache.RedisCl
 		})
 	})
 
+	// uploaded files are served from disk when not kept in S3
+	if cfg.Storage.Driver == "" || cfg.Storage.Driver == "local" {
+		router.Static("/uploads", cfg.Storage.LocalDir)
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
-		setupAuthRoutes(v1, services.UserService, redisClient, cfg)
-		setupPropertyRoutes(v1, services.PropertyService, services.UserService, redisClient, cfg)
+		setupAuthRoutes(v1, services, redisClient, cfg)
+		setupUserRoutes(v1, services)
+		setupPropertyRoutes(v1, services, redisClient, cfg)
+		setupHostRoutes(v1, services)
+		setupSearchRoutes(v1, services, redisClient, cfg)
+		setupWishlistRoutes(v1, services.WishlistService, services.UserService)
+		setupAmenityRoutes(v1, services.AmenityService)
 		setupBookingRoutes(v1, services.BookingService, services.UserService, redisClient, cfg)
 		setupReviewRoutes(v1, services.ReviewService, services.UserService, redisClient, cfg)
+		setupAdminRoutes(v1, services, redisClient, cfg)
 	}
 
 	return router
 }
 
 // sets up authentication routes
-func setupAuthRoutes(rg *gin.RouterGroup, userService *service.UserService, redisClient *cache.RedisClient, cfg *config.Config) {
+func setupAuthRoutes(rg *gin.RouterGroup, services Services, redisClient *cache.RedisClient, cfg *config.Config) {
 	auth := rg.Group("/auth")
-	handler := NewUserHandler(userService)
+	handler := NewUserHandler(services.UserService, services.RecentlyViewedService)
 
 	// Apply stricter rate limiting for auth endpoints
 	authRateLimit := middleware.CreateRateLimiterForEndpoint(redisClient, cfg.RateLimit.AuthRequestsPerMinute, "auth")
@@ -71,35 +93,153 @@ func setupAuthRoutes(rg *gin.RouterGroup, userService *service.UserService, redi
 	auth.POST("/refresh", handler.RefreshToken)
 }
 
-func setupPropertyRoutes(rg *gin.RouterGroup, propertyService *service.PropertyService, userService *service.UserService, redisClient *cache.RedisClient, cfg *config.Config) {
+func setupUserRoutes(rg *gin.RouterGroup, services Services) {
+	users := rg.Group("/users")
+	users.Use(middleware.AuthMiddleware(services.UserService))
+	handler := NewUserHandler(services.UserService, services.RecentlyViewedService)
+
+	users.GET("/me/recently-viewed", handler.GetRecentlyViewed)
+	users.DELETE("/me/recently-viewed", handler.ClearRecentlyViewed)
+
+	savedSearches := users.Group("/me/saved-searches")
+	savedSearchHandler := NewSavedSearchHandler(services.SavedSearchService)
+	{
+		savedSearches.GET("/", savedSearchHandler.GetMySavedSearches)
+		savedSearches.POST("/", savedSearchHandler.CreateSavedSearch)
+		savedSearches.GET("/:id", savedSearchHandler.GetSavedSearch)
+		savedSearches.PUT("/:id", savedSearchHandler.UpdateSavedSearch)
+		savedSearches.DELETE("/:id", savedSearchHandler.DeleteSavedSearch)
+	}
+
+	// the token comes from the invitation email
+	cohostHandler := NewCoHostHandler(services.CoHostService)
+	users.POST("/me/cohost-invitations/accept", cohostHandler.AcceptInvitation)
+}
+
+func setupPropertyRoutes(rg *gin.RouterGroup, services Services, redisClient *cache.RedisClient, cfg *config.Config) {
 	properties := rg.Group("/properties")
-	handler := NewPropertyHandler(propertyService)
+	handler := NewPropertyHandler(services.PropertyService, services.WishlistService, services.RecentlyViewedService, services.PropertyTranslationService)
 
-	// Public routes with moderate rate limiting
-	properties.GET("/", handler.ListProperties)
-	properties.GET("/search", middleware.CreateRateLimiterForEndpoint(redisClient, cfg.RateLimit.SearchRequestsPerMinute, "search"), handler.SearchProperties)
-	properties.GET("/:id", handler.GetProperty)
+	// Public routes with moderate rate limiting. Signed in guests also get
+	// is_favorited on the listings.
+	optionalAuth := middleware.OptionalAuthMiddleware(services.UserService)
+	properties.GET("/", optionalAuth, handler.ListProperties)
+	properties.GET("/search", middleware.CreateRateLimiterForEndpoint(redisClient, cfg.RateLimit.SearchRequestsPerMinute, "search"), optionalAuth, handler.SearchProperties)
+	properties.GET("/:id", optionalAuth, handler.GetProperty)
 	properties.GET("/:id/availability", handler.CheckAvailability)
+	properties.GET("/:id/similar", optionalAuth, handler.GetSimilarProperties)
+
+	imageHandler := NewPropertyImageHandler(services.PropertyImageService)
+	moderationHandler := NewModerationHandler(services.ModerationService)
+	revisionHandler := NewPropertyRevisionHandler(services.PropertyRevisionService)
+	translationHandler := NewPropertyTranslationHandler(services.PropertyTranslationService)
+	cohostHandler := NewCoHostHandler(services.CoHostService)
+	properties.GET("/:id/images", optionalAuth, imageHandler.GetPropertyImages)
 
 	// Protected routes
 	protected := properties.Group("/")
-	protected.Use(middleware.AuthMiddleware(userService))
+	protected.Use(middleware.AuthMiddleware(services.UserService))
 	{
 		protected.POST("/", middleware.RequireRole("host", "admin"), handler.CreateProperty)
 		protected.PUT("/:id", handler.UpdateProperty)
 		protected.DELETE("/:id", handler.DeleteProperty)
 		protected.GET("/my", handler.GetMyProperties)
 
+		// listings saved as drafts and filled in step by step
+		protected.POST("/drafts", middleware.RequireRole("host", "admin"), handler.CreateDraft)
+		protected.GET("/:id/draft", handler.GetDraft)
+		protected.PATCH("/:id/draft/basics", handler.UpdateDraftBasics)
+		protected.PATCH("/:id/draft/location", handler.UpdateDraftLocation)
+		protected.PATCH("/:id/draft/capacity", handler.UpdateDraftCapacity)
+		protected.PATCH("/:id/draft/amenities", handler.UpdateDraftAmenities)
+		protected.PATCH("/:id/draft/pricing", handler.UpdateDraftPricing)
+		protected.PATCH("/:id/draft/rules", handler.UpdateDraftRules)
+		protected.POST("/:id/submit", handler.SubmitProperty)
+		protected.GET("/:id/moderation", moderationHandler.GetDecisions)
+
+		// listing photos, managed by the host
+		protected.POST("/:id/images", imageHandler.UploadImage)
+		protected.PUT("/:id/images/order", imageHandler.ReorderImages)
+		protected.PATCH("/:id/images/:image_id", imageHandler.UpdateImage)
+		protected.DELETE("/:id/images/:image_id", imageHandler.DeleteImage)
+
+		// snapshots of the listing taken whenever it changed
+		protected.GET("/:id/revisions", revisionHandler.GetRevisions)
+		protected.GET("/:id/revisions/:revision_id", revisionHandler.GetRevision)
+		protected.POST("/:id/revisions/:revision_id/rollback", handler.RollbackProperty)
+
+		// the title, description and rules in other languages
+		protected.GET("/:id/translations", translationHandler.GetTranslations)
+		protected.PUT("/:id/translations/:locale", translationHandler.SetTranslation)
+		protected.DELETE("/:id/translations/:locale", translationHandler.DeleteTranslation)
+
+		// users who help the host manage the listing
+		protected.GET("/:id/cohosts", cohostHandler.GetCoHosts)
+		protected.PUT("/:id/cohosts/:user_id", cohostHandler.UpdateCoHost)
+		protected.DELETE("/:id/cohosts/:user_id", cohostHandler.RemoveCoHost)
+		protected.GET("/:id/cohost-invitations", cohostHandler.GetInvitations)
+		protected.POST("/:id/cohost-invitations", cohostHandler.InviteCoHost)
+		protected.DELETE("/:id/cohost-invitations/:invitation_id", cohostHandler.RevokeInvitation)
+
 		// Admin only routes
 		admin := protected.Group("/")
 		admin.Use(middleware.RequireRole("admin"))
 		{
-			admin.POST("/:id/approve", handler.ApproveProperty)
+			admin.POST("/:id/approve", moderationHandler.ApproveProperty)
 		}
 
 	}
 }
 
+// sets up the dashboard of hosts and co-hosts
+func setupHostRoutes(rg *gin.RouterGroup, services Services) {
+	hosts := rg.Group("/hosts")
+	hosts.Use(middleware.AuthMiddleware(services.UserService))
+	analyticsHandler := NewHostAnalyticsHandler(services.HostAnalyticsService)
+
+	hosts.GET("/me/analytics", analyticsHandler.GetMyAnalytics)
+}
+
+func setupSearchRoutes(rg *gin.RouterGroup, services Services, redisClient *cache.RedisClient, cfg *config.Config) {
+	search := rg.Group("/search")
+	search.Use(middleware.CreateRateLimiterForEndpoint(redisClient, cfg.RateLimit.SearchRequestsPerMinute, "search"))
+	handler := NewPropertyHandler(services.PropertyService, services.WishlistService, services.RecentlyViewedService, services.PropertyTranslationService)
+
+	search.GET("/suggest", handler.SuggestDestinations)
+}
+
+func setupWishlistRoutes(rg *gin.RouterGroup, wishlistService *service.WishlistService, userService *service.UserService) {
+	wishlists := rg.Group("/wishlists")
+	handler := NewWishlistHandler(wishlistService)
+
+	// Public share links
+	wishlists.GET("/shared/:token", handler.GetSharedWishlist)
+
+	protected := wishlists.Group("/")
+	protected.Use(middleware.AuthMiddleware(userService))
+	{
+		protected.GET("/", handler.GetMyWishlists)
+		protected.POST("/", handler.CreateWishlist)
+		protected.GET("/:id", handler.GetWishlist)
+		protected.PUT("/:id", handler.UpdateWishlist)
+		protected.DELETE("/:id", handler.DeleteWishlist)
+		protected.POST("/:id/items", handler.AddItem)
+		protected.PUT("/:id/items/:item_id", handler.UpdateItem)
+		protected.DELETE("/:id/items/:item_id", handler.RemoveItem)
+		protected.POST("/:id/collaborators", handler.AddCollaborator)
+		protected.DELETE("/:id/collaborators/:user_id", handler.RemoveCollaborator)
+	}
+}
+
+// sets up the public amenity catalog
+func setupAmenityRoutes(rg *gin.RouterGroup, amenityService *service.AmenityService) {
+	amenities := rg.Group("/amenities")
+	handler := NewAmenityHandler(amenityService)
+
+	amenities.GET("/", handler.ListAmenities)
+	amenities.GET("/:id", handler.GetAmenity)
+}
+
 func setupBookingRoutes(rg *gin.RouterGroup, bookingService *service.BookingService, userService *service.UserService, redisClient *cache.RedisClient, cfg *config.Config) {
 	bookings := rg.Group("/bookings")
 	bookings.Use(middleware.AuthMiddleware(userService))
@@ -108,6 +248,7 @@ func setupBookingRoutes(rg *gin.RouterGroup, bookingService *service.BookingServ
 	// Apply stricter rate limiting for booking creation
 	bookings.POST("/", middleware.CreateRateLimiterForEndpoint(redisClient, cfg.RateLimit.BookingRequestsPerMinute, "create_booking"), handler.CreateBooking)
 	bookings.GET("/:id", handler.GetBooking)
+	bookings.GET("/:id/receipt", handler.GetBookingReceipt)
 	bookings.PUT("/:id", handler.UpdateBooking)
 	bookings.POST("/:id/cancel", handler.CancelBooking)
 	bookings.GET("/my", handler.GetMyBookings)
@@ -123,6 +264,8 @@ func setupReviewRoutes(rg *gin.RouterGroup, reviewService *service.ReviewService
 	reviews := rg.Group("/reviews")
 	handler := NewReviewHandler(reviewService)
 
+	// Public routes
+
 	protected := reviews.Group("/")
 	protected.Use(middleware.AuthMiddleware(userService))
 	{
@@ -133,3 +276,39 @@ func setupReviewRoutes(rg *gin.RouterGroup, reviewService *service.ReviewService
 		protected.DELETE("/:id", handler.DeleteReview)
 	}
 }
+
+func setupAdminRoutes(rg *gin.RouterGroup, services Services, redisClient *cache.RedisClient, cfg *config.Config) {
+	admin := rg.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(services.UserService))
+	admin.Use(middleware.RequireRole("admin"))
+
+	amenities := admin.Group("/amenities")
+	amenityHandler := NewAmenityHandler(services.AmenityService)
+	{
+		amenities.POST("/", amenityHandler.CreateAmenity)
+		amenities.GET("/unmapped", amenityHandler.GetUnmappedAmenities)
+		amenities.PUT("/:id", amenityHandler.UpdateAmenity)
+		amenities.DELETE("/:id", amenityHandler.DeleteAmenity)
+	}
+
+	// moderation of new and resubmitted listings
+	properties := admin.Group("/properties")
+	moderationHandler := NewModerationHandler(services.ModerationService)
+	{
+		properties.GET("/pending", moderationHandler.GetQueue)
+		properties.GET("/rejection-reasons", moderationHandler.ListRejectionReasons)
+		properties.POST("/:id/approve", moderationHandler.ApproveProperty)
+		properties.POST("/:id/reject", moderationHandler.RejectProperty)
+		properties.GET("/:id/decisions", moderationHandler.GetDecisions)
+	}
+
+	taxRules := admin.Group("/tax-rules")
+	taxRuleHandler := NewTaxRuleHandler(services.TaxRuleService)
+	{
+		taxRules.GET("/", taxRuleHandler.ListTaxRules)
+		taxRules.POST("/", taxRuleHandler.CreateTaxRule)
+		taxRules.GET("/:id", taxRuleHandler.GetTaxRule)
+		taxRules.PUT("/:id", taxRuleHandler.UpdateTaxRule)
+		taxRules.DELETE("/:id", taxRuleHandler.DeleteTaxRule)
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"airbnb-clone/internal/middleware"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SavedSearchHandler struct {
+	savedSearchService *service.SavedSearchService
+}
+
+func NewSavedSearchHandler(savedSearchService *service.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchService: savedSearchService,
+	}
+}
+
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.SavedSearchCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// alerts match q in the language it was saved in
+	req.Criteria.Locale = searchLocale(c)
+
+	search, err := h.savedSearchService.CreateSavedSearch(userID, &req)
+	if err != nil {
+		if isSavedSearchValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+func (h *SavedSearchHandler) GetSavedSearch(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	searchIDStr := c.Param("id")
+	searchID, err := uuid.Parse(searchIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	search, err := h.savedSearchService.GetSavedSearch(searchID, userID)
+	if err != nil {
+		if err.Error() == "saved search not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) GetMySavedSearches(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
+
+	searches, nextCursor, err := h.savedSearchService.GetUserSavedSearches(userID, page, limit, cursor)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"saved_searches": searches,
+		"page":           page,
+		"limit":          limit,
+		"next_cursor":    nextCursor,
+	})
+}
+
+func (h *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	searchIDStr := c.Param("id")
+	searchID, err := uuid.Parse(searchIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	var req models.SavedSearchUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Criteria != nil {
+		req.Criteria.Locale = searchLocale(c)
+	}
+
+	search, err := h.savedSearchService.UpdateSavedSearch(searchID, userID, &req)
+	if err != nil {
+		if err.Error() == "saved search not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if isSavedSearchValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	searchIDStr := c.Param("id")
+	searchID, err := uuid.Parse(searchIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	err = h.savedSearchService.DeleteSavedSearch(searchID, userID)
+	if err != nil {
+		if err.Error() == "saved search not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted successfully"})
+}
+
+func isSavedSearchValidationError(err error) bool {
+	if service.IsUnknownAmenitiesError(err) || service.IsUnknownAccessibilityFeatureError(err) {
+		return true
+	}
+	switch err.Error() {
+	case "saved search name is required",
+		"invalid lat",
+		"invalid lng",
+		"lat and lng must be provided together",
+		fmt.Sprintf("invalid radius_km. Must be between 0 and %d", models.MaxSearchRadiusKm),
+		"invalid bbox latitude range",
+		"invalid bbox longitude range",
+		"radius_km requires lat and lng",
+		"invalid sort",
+		"sort by distance requires lat and lng",
+		"sort by relevance requires q",
+		"check-in and check-out dates must be given together",
+		"check-out date must be after check-in date":
+		return true
+	}
+	return false
+}
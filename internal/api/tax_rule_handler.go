@@ -0,0 +1,151 @@
+package api
+
+import (
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TaxRuleHandler struct {
+	taxRuleService *service.TaxRuleService
+}
+
+func NewTaxRuleHandler(taxRuleService *service.TaxRuleService) *TaxRuleHandler {
+	return &TaxRuleHandler{
+		taxRuleService: taxRuleService,
+	}
+}
+
+func (h *TaxRuleHandler) CreateTaxRule(c *gin.Context) {
+	var req models.TaxRuleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.taxRuleService.CreateTaxRule(&req)
+	if err != nil {
+		if isTaxRuleValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *TaxRuleHandler) GetTaxRule(c *gin.Context) {
+	ruleIDStr := c.Param("id")
+	ruleID, err := uuid.Parse(ruleIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tax rule ID"})
+		return
+	}
+
+	rule, err := h.taxRuleService.GetTaxRule(ruleID)
+	if err != nil {
+		if err.Error() == "tax rule not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *TaxRuleHandler) UpdateTaxRule(c *gin.Context) {
+	ruleIDStr := c.Param("id")
+	ruleID, err := uuid.Parse(ruleIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tax rule ID"})
+		return
+	}
+
+	var req models.TaxRuleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.taxRuleService.UpdateTaxRule(ruleID, &req)
+	if err != nil {
+		if err.Error() == "tax rule not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if isTaxRuleValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *TaxRuleHandler) DeleteTaxRule(c *gin.Context) {
+	ruleIDStr := c.Param("id")
+	ruleID, err := uuid.Parse(ruleIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tax rule ID"})
+		return
+	}
+
+	err = h.taxRuleService.DeleteTaxRule(ruleID)
+	if err != nil {
+		if err.Error() == "tax rule not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tax rule deleted successfully"})
+}
+
+func (h *TaxRuleHandler) ListTaxRules(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	cursor := c.Query("cursor")
+
+	rules, nextCursor, err := h.taxRuleService.ListTaxRules(page, limit, cursor)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tax_rules":   rules,
+		"page":        page,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func isTaxRuleValidationError(err error) bool {
+	switch err.Error() {
+	case "tax rule name is required",
+		"tax rule country is required",
+		"tax rule state is required when city is set",
+		"percentage tax rate cannot exceed 100",
+		"invalid tax rule type",
+		"tax rate cannot be negative",
+		"max nights cannot be negative":
+		return true
+	}
+	return false
+}
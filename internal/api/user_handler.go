@@ -2,7 +2,9 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
+	"airbnb-clone/internal/middleware"
 	"airbnb-clone/internal/models"
 	"airbnb-clone/internal/service"
 	"airbnb-clone/internal/utils"
@@ -11,12 +13,14 @@ import (
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService           *service.UserService
+	recentlyViewedService *service.RecentlyViewedService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, recentlyViewedService *service.RecentlyViewedService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:           userService,
+		recentlyViewedService: recentlyViewedService,
 	}
 }
 
@@ -90,3 +94,40 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 
 	c.JSON(http.StatusOK, loginResponse)
 }
+
+func (h *UserHandler) GetRecentlyViewed(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	properties, err := h.recentlyViewedService.GetRecentlyViewed(userID, limit)
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"properties": properties})
+}
+
+func (h *UserHandler) ClearRecentlyViewed(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.recentlyViewedService.ClearRecentlyViewed(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recently viewed properties cleared"})
+}
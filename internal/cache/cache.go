@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"airbnb-clone/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// schemaVersion is part of every cache key. Bump it whenever the shape of a
+// cached value changes so entries written by older builds are never decoded.
+const schemaVersion = "v1"
+
+// setIfVersion stores a loaded value only while the version of its id is the
+// one read before loading, so a load that raced with Evict or Set never
+// writes what it read before the mutation
+var setIfVersion = redis.NewScript(`
+local current = redis.call("GET", KEYS[2]) or "0"
+if current ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// Cache is a read-through cache on top of Redis. Concurrent misses for the
+// same key are collapsed into a single load to protect the database from
+// stampedes when a hot entry expires or is evicted.
+type Cache struct {
+	redis *RedisClient
+	group singleflight.Group
+}
+
+// New creates a cache backed by the given Redis client
+func New(redisClient *RedisClient) *Cache {
+	return &Cache{redis: redisClient}
+}
+
+// Namespace is a family of cached values sharing a TTL. Entries are keyed as
+// cache:<schema version>:<namespace>:<generation>:<id>; single entries are
+// evicted by id and the whole namespace is dropped by bumping its generation.
+type Namespace struct {
+	cache *Cache
+	name  string
+	ttl   time.Duration
+}
+
+// Namespace returns the namespace name whose entries live for ttl
+func (c *Cache) Namespace(name string, ttl time.Duration) *Namespace {
+	return &Namespace{
+		cache: c,
+		name:  name,
+		ttl:   ttl,
+	}
+}
+
+func (n *Namespace) generationKey() string {
+	return fmt.Sprintf("cache:%s:%s:generation", schemaVersion, n.name)
+}
+
+func (n *Namespace) key(id string) (string, error) {
+	generation, err := n.cache.redis.Get(n.generationKey())
+	if err == redis.Nil {
+		generation = "0"
+	} else if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("cache:%s:%s:%s:%s", schemaVersion, n.name, generation, id), nil
+}
+
+// versionKey counts the mutations of id. It is independent of the generation
+// so it survives InvalidateAll.
+func (n *Namespace) versionKey(id string) string {
+	return fmt.Sprintf("cache:%s:%s:version:%s", schemaVersion, n.name, id)
+}
+
+func (n *Namespace) version(id string) (string, error) {
+	version, err := n.cache.redis.Get(n.versionKey(id))
+	if err == redis.Nil {
+		return "0", nil
+	}
+	return version, err
+}
+
+// bumpVersion makes loads of id that are already in flight drop their result.
+// The counter outlives every entry written before it changed.
+func (n *Namespace) bumpVersion(id string) error {
+	versionKey := n.versionKey(id)
+	if _, err := n.cache.redis.Incr(versionKey); err != nil {
+		return err
+	}
+	if n.ttl > 0 {
+		return n.cache.redis.Expire(versionKey, n.ttl)
+	}
+	return nil
+}
+
+// GetOrLoad returns the cached value for id, calling load and caching its
+// result on a miss. Redis failures are logged and fall back to load, so the
+// cache never makes a read fail. Errors from load are returned and not cached.
+func GetOrLoad[T any](n *Namespace, id string, load func() (T, error)) (T, error) {
+	// the generation is read once, and the result is stored under the key it
+	// was looked up with, so a load that overlaps InvalidateAll lands in the
+	// old generation where no reader looks
+	key, err := n.key(id)
+	if err != nil {
+		logger.Warnf("cache %s unavailable: %v", n.name, err)
+		return load()
+	}
+
+	if cached, err := n.cache.redis.Get(key); err == nil {
+		var value T
+		if err := json.Unmarshal([]byte(cached), &value); err == nil {
+			return value, nil
+		}
+		logger.Warnf("failed to decode cached %s %s: %v", n.name, id, err)
+	} else if err != redis.Nil {
+		logger.Warnf("failed to read cached %s %s: %v", n.name, id, err)
+	}
+
+	result, err, _ := n.cache.group.Do(key, func() (interface{}, error) {
+		version, versionErr := n.version(id)
+		value, err := load()
+		if err != nil {
+			return value, err
+		}
+		if versionErr != nil {
+			logger.Warnf("failed to read version of cached %s %s: %v", n.name, id, versionErr)
+		} else if err := n.setIfVersion(key, id, version, value); err != nil {
+			logger.Warnf("failed to cache %s %s: %v", n.name, id, err)
+		}
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result.(T), nil
+}
+
+// Set writes value through to the cache under id. Loads of id already in
+// flight will not overwrite it.
+func (n *Namespace) Set(id string, value interface{}) error {
+	key, err := n.key(id)
+	if err != nil {
+		return err
+	}
+
+	n.cache.group.Forget(key)
+	if err := n.bumpVersion(id); err != nil {
+		return err
+	}
+	return n.set(key, value)
+}
+
+func (n *Namespace) set(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached value: %w", err)
+	}
+
+	return n.cache.redis.Set(key, string(data), n.ttl)
+}
+
+func (n *Namespace) setIfVersion(key, id, version string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached value: %w", err)
+	}
+
+	_, err = n.cache.redis.RunScript(setIfVersion, []string{key, n.versionKey(id)}, version, string(data), n.ttl.Milliseconds())
+	return err
+}
+
+// Evict removes the entry for id. A load already in flight for id is
+// forgotten so later readers do not join it and receive pre-mutation data,
+// and its result is not cached once it finishes.
+func (n *Namespace) Evict(id string) error {
+	key, err := n.key(id)
+	if err != nil {
+		return err
+	}
+
+	n.cache.group.Forget(key)
+	if err := n.bumpVersion(id); err != nil {
+		return err
+	}
+	return n.cache.redis.Del(key)
+}
+
+// InvalidateAll drops every entry in the namespace. Old entries are left to
+// expire on their own.
+func (n *Namespace) InvalidateAll() error {
+	_, err := n.cache.redis.Incr(n.generationKey())
+	return err
+}
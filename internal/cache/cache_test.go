@@ -0,0 +1,344 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"airbnb-clone/internal/config"
+)
+
+// fakeRedis speaks just enough RESP2 for the commands the cache sends. EVAL
+// runs setIfVersion, the only script the cache has, in Go.
+type fakeRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+func startFakeRedis(t *testing.T) (*fakeRedis, *RedisClient) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeRedis{values: map[string]string{}, ttls: map[string]time.Duration{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	client, err := NewRedisClient(config.RedisConfig{Host: host, Port: port})
+	if err != nil {
+		t.Fatalf("failed to connect to fake redis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return server, client
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, f.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("expected an array")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func integer(value int64) string {
+	return fmt.Sprintf(":%d\r\n", value)
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := f.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(value)
+	case "SET":
+		f.values[args[1]] = args[2]
+		delete(f.ttls, args[1])
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			ms, _ := strconv.Atoi(args[4])
+			f.ttls[args[1]] = time.Duration(ms) * time.Millisecond
+		}
+		return "+OK\r\n"
+	case "DEL":
+		_, ok := f.values[args[1]]
+		delete(f.values, args[1])
+		delete(f.ttls, args[1])
+		if ok {
+			return integer(1)
+		}
+		return integer(0)
+	case "INCR":
+		current, _ := strconv.ParseInt(f.values[args[1]], 10, 64)
+		f.values[args[1]] = strconv.FormatInt(current+1, 10)
+		return integer(current + 1)
+	case "EXPIRE":
+		seconds, _ := strconv.Atoi(args[2])
+		f.ttls[args[1]] = time.Duration(seconds) * time.Second
+		return integer(1)
+	case "EVALSHA":
+		return "-NOSCRIPT No matching script\r\n"
+	case "EVAL":
+		// EVAL script 2 key versionKey version data ttl
+		key, versionKey, version, data := args[3], args[4], args[5], args[6]
+		current, ok := f.values[versionKey]
+		if !ok {
+			current = "0"
+		}
+		if current != version {
+			return integer(0)
+		}
+		f.values[key] = data
+		ms, _ := strconv.Atoi(args[7])
+		f.ttls[key] = time.Duration(ms) * time.Millisecond
+		return integer(1)
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+func (f *fakeRedis) get(key string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	value, ok := f.values[key]
+	return value, ok
+}
+
+func (f *fakeRedis) ttl(key string) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ttls[key]
+}
+
+// counts the loads and returns the value the test sets for the next one
+type loader struct {
+	calls  int
+	value  string
+	during func()
+}
+
+func (l *loader) load() (string, error) {
+	l.calls++
+	if l.during != nil {
+		l.during()
+	}
+	return l.value, nil
+}
+
+func TestVersion(t *testing.T) {
+	server, client := startFakeRedis(t)
+	n := New(client).Namespace("properties", time.Minute)
+
+	version, err := n.version("1")
+	if err != nil || version != "0" {
+		t.Fatalf("version of unknown id = %q, %v, want \"0\"", version, err)
+	}
+
+	for want := 1; want <= 2; want++ {
+		if err := n.bumpVersion("1"); err != nil {
+			t.Fatalf("bumpVersion: %v", err)
+		}
+		version, err := n.version("1")
+		if err != nil || version != strconv.Itoa(want) {
+			t.Fatalf("version after %d bumps = %q, %v", want, version, err)
+		}
+	}
+	if ttl := server.ttl(n.versionKey("1")); ttl != time.Minute {
+		t.Errorf("version expires after %v, want %v", ttl, time.Minute)
+	}
+
+	if version, _ := n.version("2"); version != "0" {
+		t.Errorf("version of another id = %q, want \"0\"", version)
+	}
+}
+
+func TestSetIfVersion(t *testing.T) {
+	server, client := startFakeRedis(t)
+	n := New(client).Namespace("properties", time.Minute)
+	key, _ := n.key("1")
+
+	tests := []struct {
+		name    string
+		version string
+		stored  bool
+	}{
+		{name: "stale version", version: "1", stored: false},
+		{name: "current version", version: "0", stored: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := n.setIfVersion(key, "1", tt.version, "value"); err != nil {
+				t.Fatalf("setIfVersion: %v", err)
+			}
+			value, ok := server.get(key)
+			if ok != tt.stored {
+				t.Fatalf("stored = %v, want %v", ok, tt.stored)
+			}
+			if ok && value != `"value"` {
+				t.Errorf("stored %s, want the JSON of the value", value)
+			}
+		})
+	}
+	if ttl := server.ttl(key); ttl != time.Minute {
+		t.Errorf("entry expires after %v, want %v", ttl, time.Minute)
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		// runs while the first load is in flight
+		during func(n *Namespace)
+		// runs between the two reads
+		between func(n *Namespace)
+		// loads made by both reads, and what the second one returns
+		wantCalls int
+		want      string
+	}{
+		{
+			name:      "cached",
+			wantCalls: 1,
+			want:      "old",
+		},
+		{
+			name:      "evicted",
+			between:   func(n *Namespace) { n.Evict("1") },
+			wantCalls: 2,
+			want:      "new",
+		},
+		{
+			name:      "evicted during load",
+			during:    func(n *Namespace) { n.Evict("1") },
+			wantCalls: 2,
+			want:      "new",
+		},
+		{
+			name:      "set during load",
+			during:    func(n *Namespace) { n.Set("1", "written") },
+			wantCalls: 1,
+			want:      "written",
+		},
+		{
+			name:      "namespace invalidated",
+			between:   func(n *Namespace) { n.InvalidateAll() },
+			wantCalls: 2,
+			want:      "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := startFakeRedis(t)
+			n := New(client).Namespace("properties", time.Minute)
+
+			l := &loader{value: "old"}
+			if tt.during != nil {
+				l.during = func() { tt.during(n) }
+			}
+			got, err := GetOrLoad(n, "1", l.load)
+			if err != nil || got != "old" {
+				t.Fatalf("first read = %q, %v, want the loaded value", got, err)
+			}
+
+			if tt.between != nil {
+				tt.between(n)
+			}
+			l.value, l.during = "new", nil
+			got, err = GetOrLoad(n, "1", l.load)
+			if err != nil {
+				t.Fatalf("second read: %v", err)
+			}
+			if got != tt.want || l.calls != tt.wantCalls {
+				t.Errorf("second read = %q after %d loads, want %q after %d", got, l.calls, tt.want, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	_, client := startFakeRedis(t)
+	n := New(client).Namespace("properties", time.Minute)
+
+	calls := 0
+	load := func() (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("database is down")
+		}
+		return "loaded", nil
+	}
+
+	if _, err := GetOrLoad(n, "1", load); err == nil {
+		t.Fatal("first read did not return the load error")
+	}
+	got, err := GetOrLoad(n, "1", load)
+	if err != nil || got != "loaded" || calls != 2 {
+		t.Errorf("second read = %q, %v after %d loads, want a fresh load", got, err, calls)
+	}
+}
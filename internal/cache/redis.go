@@ -84,6 +84,35 @@ func (r *RedisClient) IncrBy(key string, value int64) (int64, error) {
 	return r.client.IncrBy(r.ctx, key, value).Result()
 }
 
+// PushUnique moves value to the front of a list, keeps its first maxLen
+// elements and resets its expiration. The steps run in one MULTI block so
+// concurrent pushes cannot leave duplicates or an untrimmed list.
+func (r *RedisClient) PushUnique(key string, value interface{}, maxLen int64, expiration time.Duration) error {
+	_, err := r.client.TxPipelined(r.ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(r.ctx, key, 0, value)
+		pipe.LPush(r.ctx, key, value)
+		pipe.LTrim(r.ctx, key, 0, maxLen-1)
+		pipe.Expire(r.ctx, key, expiration)
+		return nil
+	})
+	return err
+}
+
+// LRem removes up to count occurrences of value from a list, all of them when count is 0
+func (r *RedisClient) LRem(key string, count int64, value interface{}) error {
+	return r.client.LRem(r.ctx, key, count, value).Err()
+}
+
+// LRange returns the elements of a list between start and stop, inclusive
+func (r *RedisClient) LRange(key string, start, stop int64) ([]string, error) {
+	return r.client.LRange(r.ctx, key, start, stop).Result()
+}
+
+// RunScript runs a Lua script, loading it into Redis on first use
+func (r *RedisClient) RunScript(script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	return script.Run(r.ctx, r.client, keys, args...).Result()
+}
+
 // GetClient returns the underlying Redis client for advanced operations
 func (r *RedisClient) GetClient() *redis.Client {
 	return r.client
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"airbnb-clone/internal/models"
+)
+
+// SearchKey hashes the normalized form of a search request, so requests that
+// differ only in letter case, whitespace or amenity order share a cache entry
+// in the search namespace
+func SearchKey(req *models.PropertySearchRequest) string {
+	amenities := make([]string, 0, len(req.Amenities))
+	seen := make(map[string]bool, len(req.Amenities))
+	for _, amenity := range req.Amenities {
+		amenity = strings.TrimSpace(amenity)
+		if amenity != "" && !seen[amenity] {
+			seen[amenity] = true
+			amenities = append(amenities, amenity)
+		}
+	}
+	sort.Strings(amenities)
+
+	accessibility := append([]string{}, req.Accessibility...)
+	sort.Strings(accessibility)
+
+	// mirror the repository's paging defaults
+	page, limit := req.Page, req.Limit
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	normalized := map[string]interface{}{
+		"q":             strings.Join(strings.Fields(strings.ToLower(req.Query)), " "),
+		"city":          normalizeText(req.City),
+		"state":         normalizeText(req.State),
+		"country":       normalizeText(req.Country),
+		"type":          strings.TrimSpace(req.Type),
+		"check_in":      formatDate(req.CheckIn),
+		"check_out":     formatDate(req.CheckOut),
+		"guests":        req.Guests,
+		"min_bedrooms":  req.MinBedrooms,
+		"min_beds":      req.MinBeds,
+		"min_price":     formatFloat(req.MinPrice),
+		"max_price":     formatFloat(req.MaxPrice),
+		"amenities":     amenities,
+		"accessibility": accessibility,
+		"radius_km":     formatFloat(req.RadiusKm),
+		"sort":          req.Sort,
+		"page":          page,
+		"limit":         limit,
+	}
+	// the language only changes which listings a text query matches
+	if req.Query != "" && req.Locale != "" {
+		normalized["locale"] = req.Locale
+	}
+	houseRules := map[string]*bool{
+		"pets_allowed":     req.PetsAllowed,
+		"smoking_allowed":  req.SmokingAllowed,
+		"events_allowed":   req.EventsAllowed,
+		"children_allowed": req.ChildrenAllowed,
+		"infants_allowed":  req.InfantsAllowed,
+	}
+	for key, value := range houseRules {
+		if value != nil {
+			normalized[key] = *value
+		}
+	}
+	if req.Lat != nil && req.Lng != nil {
+		normalized["lat"] = formatFloat(*req.Lat)
+		normalized["lng"] = formatFloat(*req.Lng)
+	}
+	if req.BBox != nil {
+		normalized["bbox"] = []string{
+			formatFloat(req.BBox.MinLng), formatFloat(req.BBox.MinLat),
+			formatFloat(req.BBox.MaxLng), formatFloat(req.BBox.MaxLat),
+		}
+	}
+
+	// encoding/json sorts map keys, so the encoding is stable
+	data, _ := json.Marshal(normalized)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeText(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -15,6 +15,10 @@ type Config struct {
 	JWT       JWTConfig
 	Redis     RedisConfig
 	RateLimit RateLimitConfig
+	Cache     CacheConfig
+	Alerts    AlertConfig
+	Storage   StorageConfig
+	Notify    NotificationConfig
 }
 
 // ServerConfig holds server configuration
@@ -32,6 +36,48 @@ type RateLimitConfig struct {
 	ReviewRequestsPerMinute  int
 }
 
+// CacheConfig holds response caching configuration
+type CacheConfig struct {
+	SearchTTLSeconds   int
+	PropertyTTLSeconds int
+	UserTTLSeconds     int
+	RatingTTLSeconds   int
+	SuggestTTLSeconds  int
+	AmenityTTLSeconds  int
+}
+
+// StorageConfig holds uploaded file storage configuration
+type StorageConfig struct {
+	// "local" or "s3"
+	Driver        string
+	LocalDir      string
+	PublicBaseURL string
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+	S3PathStyle   bool
+	MaxImageMB    int
+}
+
+// NotificationConfig holds notification delivery configuration
+type NotificationConfig struct {
+	// "log" or "smtp"
+	Driver       string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	// e.g. "Airbnb Clone <no-reply@example.com>"
+	SMTPFrom string
+}
+
+// AlertConfig holds background alerting configuration
+type AlertConfig struct {
+	SavedSearchIntervalMinutes int
+}
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	Host     string
@@ -90,6 +136,37 @@ func Load() *Config {
 			BookingRequestsPerMinute: getEnvAsInt("RATE_LIMIT_BOOKING", 5),
 			ReviewRequestsPerMinute:  getEnvAsInt("RATE_LIMIT_REVIEW", 10),
 		},
+		Cache: CacheConfig{
+			SearchTTLSeconds:   getEnvAsInt("CACHE_SEARCH_TTL_SECONDS", 60),
+			PropertyTTLSeconds: getEnvAsInt("CACHE_PROPERTY_TTL_SECONDS", 600),
+			UserTTLSeconds:     getEnvAsInt("CACHE_USER_TTL_SECONDS", 600),
+			RatingTTLSeconds:   getEnvAsInt("CACHE_RATING_TTL_SECONDS", 600),
+			SuggestTTLSeconds:  getEnvAsInt("CACHE_SUGGEST_TTL_SECONDS", 300),
+			AmenityTTLSeconds:  getEnvAsInt("CACHE_AMENITY_TTL_SECONDS", 3600),
+		},
+		Alerts: AlertConfig{
+			SavedSearchIntervalMinutes: getEnvAsInt("SAVED_SEARCH_ALERT_INTERVAL_MINUTES", 15),
+		},
+		Storage: StorageConfig{
+			Driver:        getEnv("STORAGE_DRIVER", "local"),
+			LocalDir:      getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			PublicBaseURL: getEnv("STORAGE_PUBLIC_BASE_URL", "http://localhost:8081/uploads"),
+			S3Endpoint:    getEnv("S3_ENDPOINT", ""),
+			S3Region:      getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:      getEnv("S3_BUCKET", ""),
+			S3AccessKey:   getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretKey:   getEnv("S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:   getEnvAsBool("S3_PATH_STYLE", false),
+			MaxImageMB:    getEnvAsInt("MAX_IMAGE_UPLOAD_MB", 10),
+		},
+		Notify: NotificationConfig{
+			Driver:       getEnv("NOTIFICATION_DRIVER", "log"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			SMTPFrom:     getEnv("SMTP_FROM", ""),
+		},
 	}
 }
 
@@ -110,6 +187,15 @@ func getEnvAsInt(name string, fallback int) int {
 	return fallback
 }
 
+// getEnvAsBool gets an environment variable as a boolean with a fallback value
+func getEnvAsBool(name string, fallback bool) bool {
+	valueStr := getEnv(name, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return fallback
+}
+
 func LoadRedisConfig() (RedisConfig, error) {
 	redisURL := os.Getenv("REDIS_URL")
 	if redisURL == "" {
@@ -0,0 +1,112 @@
+package database
+
+import (
+	applogger "airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// the catalog a fresh database starts with. Admins manage it from then on,
+// so entries that already exist are left alone.
+var defaultAmenities = []models.Amenity{
+	{ID: "wifi", Category: "essentials", Icon: "wifi", Names: models.LocalizedNames{"en": "Wifi", "es": "Wifi", "fr": "Wi-Fi", "de": "WLAN"}, Aliases: []string{"wireless", "internet", "wlan"}},
+	{ID: "kitchen", Category: "essentials", Icon: "kitchen", Names: models.LocalizedNames{"en": "Kitchen", "es": "Cocina", "fr": "Cuisine", "de": "Küche"}},
+	{ID: "washer", Category: "essentials", Icon: "washer", Names: models.LocalizedNames{"en": "Washer", "es": "Lavadora", "fr": "Lave-linge", "de": "Waschmaschine"}, Aliases: []string{"washingmachine", "laundry"}},
+	{ID: "dryer", Category: "essentials", Icon: "dryer", Names: models.LocalizedNames{"en": "Dryer", "es": "Secadora", "fr": "Sèche-linge", "de": "Trockner"}},
+	{ID: "air_conditioning", Category: "essentials", Icon: "snowflake", Names: models.LocalizedNames{"en": "Air conditioning", "es": "Aire acondicionado", "fr": "Climatisation", "de": "Klimaanlage"}, Aliases: []string{"ac", "aircon"}},
+	{ID: "heating", Category: "essentials", Icon: "thermometer", Names: models.LocalizedNames{"en": "Heating", "es": "Calefacción", "fr": "Chauffage", "de": "Heizung"}, Aliases: []string{"heater"}},
+	{ID: "tv", Category: "features", Icon: "tv", Names: models.LocalizedNames{"en": "TV", "es": "Televisión", "fr": "Télévision", "de": "Fernseher"}, Aliases: []string{"television", "cabletv"}},
+	{ID: "workspace", Category: "features", Icon: "desk", Names: models.LocalizedNames{"en": "Dedicated workspace", "es": "Zona de trabajo", "fr": "Espace de travail", "de": "Arbeitsplatz"}, Aliases: []string{"dedicatedworkspace", "desk"}},
+	{ID: "pool", Category: "features", Icon: "pool", Names: models.LocalizedNames{"en": "Pool", "es": "Piscina", "fr": "Piscine", "de": "Pool"}, Aliases: []string{"swimmingpool"}},
+	{ID: "hot_tub", Category: "features", Icon: "hot-tub", Names: models.LocalizedNames{"en": "Hot tub", "es": "Jacuzzi", "fr": "Jacuzzi", "de": "Whirlpool"}, Aliases: []string{"jacuzzi", "spa"}},
+	{ID: "gym", Category: "features", Icon: "dumbbell", Names: models.LocalizedNames{"en": "Gym", "es": "Gimnasio", "fr": "Salle de sport", "de": "Fitnessraum"}, Aliases: []string{"fitnesscenter"}},
+	{ID: "fireplace", Category: "features", Icon: "fire", Names: models.LocalizedNames{"en": "Indoor fireplace", "es": "Chimenea", "fr": "Cheminée", "de": "Kamin"}, Aliases: []string{"indoorfireplace"}},
+	{ID: "bbq_grill", Category: "outdoor", Icon: "grill", Names: models.LocalizedNames{"en": "BBQ grill", "es": "Parrilla", "fr": "Barbecue", "de": "Grill"}, Aliases: []string{"bbq", "grill", "barbecue"}},
+	{ID: "patio", Category: "outdoor", Icon: "patio", Names: models.LocalizedNames{"en": "Patio or balcony", "es": "Patio o balcón", "fr": "Patio ou balcon", "de": "Terrasse oder Balkon"}, Aliases: []string{"balcony", "terrace"}},
+	{ID: "beach_access", Category: "location", Icon: "beach", Names: models.LocalizedNames{"en": "Beach access", "es": "Acceso a la playa", "fr": "Accès à la plage", "de": "Strandzugang"}, Aliases: []string{"beachfront", "beach"}},
+	{ID: "free_parking", Category: "parking", Icon: "car", Names: models.LocalizedNames{"en": "Free parking on premises", "es": "Aparcamiento gratuito", "fr": "Parking gratuit", "de": "Kostenloser Parkplatz"}, Aliases: []string{"parking", "freeparking"}},
+	{ID: "ev_charger", Category: "parking", Icon: "plug", Names: models.LocalizedNames{"en": "EV charger", "es": "Cargador para vehículos eléctricos", "fr": "Borne de recharge", "de": "Ladestation"}, Aliases: []string{"evcharging"}},
+	{ID: "crib", Category: "family", Icon: "crib", Names: models.LocalizedNames{"en": "Crib", "es": "Cuna", "fr": "Lit pour bébé", "de": "Kinderbett"}, Aliases: []string{"cot"}},
+	{ID: "smoke_alarm", Category: "safety", Icon: "alarm", Names: models.LocalizedNames{"en": "Smoke alarm", "es": "Detector de humo", "fr": "Détecteur de fumée", "de": "Rauchmelder"}, Aliases: []string{"smokedetector"}},
+	{ID: "carbon_monoxide_alarm", Category: "safety", Icon: "alarm", Names: models.LocalizedNames{"en": "Carbon monoxide alarm", "es": "Detector de monóxido de carbono", "fr": "Détecteur de monoxyde de carbone", "de": "Kohlenmonoxidmelder"}, Aliases: []string{"codetector", "coalarm"}},
+	{ID: "first_aid_kit", Category: "safety", Icon: "first-aid", Names: models.LocalizedNames{"en": "First aid kit", "es": "Botiquín", "fr": "Trousse de premiers secours", "de": "Erste-Hilfe-Set"}},
+	{ID: "fire_extinguisher", Category: "safety", Icon: "extinguisher", Names: models.LocalizedNames{"en": "Fire extinguisher", "es": "Extintor", "fr": "Extincteur", "de": "Feuerlöscher"}},
+}
+
+func seedAmenities(db *gorm.DB) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultAmenities).Error
+}
+
+// maps the free-text amenities of existing listings to catalog ids. Values
+// that match nothing are kept aside for an admin rather than dropped.
+func migratePropertyAmenities(db *gorm.DB) error {
+	if _, err := repository.NewAmenityRepository(db).RemapPropertyAmenities(); err != nil {
+		return err
+	}
+
+	var unmapped int64
+	err := db.Raw("SELECT COUNT(DISTINCT value) FROM properties, unnest(properties.unmapped_amenities) AS value").Scan(&unmapped).Error
+	if err != nil {
+		return err
+	}
+	if unmapped > 0 {
+		applogger.Warnf("%d listing amenities match no catalog entry and wait to be mapped by an admin", unmapped)
+	}
+
+	return nil
+}
+
+// moves the amenities that are also listing fields to those fields, pets to
+// the house rules and the elevator to the accessibility features, so search
+// filters find them. A house rule the host stated is left as it is. Saved
+// searches filtering on them are moved the same way and the catalog entries
+// are removed.
+func migrateListingFieldAmenities(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE properties SET
+				pets_allowed = CASE WHEN ? = ANY(amenities) THEN COALESCE(pets_allowed, true) ELSE pets_allowed END,
+				accessibility_features = CASE
+					WHEN ? = ANY(amenities) AND NOT ? = ANY(COALESCE(accessibility_features, '{}'))
+					THEN array_append(COALESCE(accessibility_features, '{}'), ?)
+					ELSE accessibility_features
+				END,
+				amenities = array_remove(array_remove(amenities, ?), ?)
+			WHERE amenities && ?
+		`, models.AmenityPetsAllowed,
+			models.AmenityElevator, string(models.AccessibilityElevator), string(models.AccessibilityElevator),
+			models.AmenityPetsAllowed, models.AmenityElevator,
+			pq.StringArray{models.AmenityPetsAllowed, models.AmenityElevator}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+			UPDATE saved_searches SET criteria = criteria
+				|| CASE WHEN criteria->'amenities' @> to_jsonb(ARRAY[?::text])
+					THEN jsonb_build_object('pets_allowed', COALESCE(criteria->'pets_allowed', 'true'::jsonb))
+					ELSE '{}'::jsonb
+				END
+				|| CASE WHEN criteria->'amenities' @> to_jsonb(ARRAY[?::text])
+					AND NOT COALESCE(criteria->'accessibility', '[]'::jsonb) @> to_jsonb(ARRAY[?::text])
+					THEN jsonb_build_object('accessibility', COALESCE(criteria->'accessibility', '[]'::jsonb) || to_jsonb(ARRAY[?::text]))
+					ELSE '{}'::jsonb
+				END
+				|| jsonb_build_object('amenities', (criteria->'amenities') - ?::text - ?::text)
+			WHERE jsonb_typeof(criteria->'amenities') = 'array'
+				AND (criteria->'amenities' @> to_jsonb(ARRAY[?::text]) OR criteria->'amenities' @> to_jsonb(ARRAY[?::text]))
+		`, models.AmenityPetsAllowed,
+			models.AmenityElevator, string(models.AccessibilityElevator), string(models.AccessibilityElevator),
+			models.AmenityPetsAllowed, models.AmenityElevator,
+			models.AmenityPetsAllowed, models.AmenityElevator).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("id IN ?", []string{models.AmenityPetsAllowed, models.AmenityElevator}).Delete(&models.Amenity{}).Error
+	})
+}
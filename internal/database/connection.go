@@ -44,16 +44,82 @@ func Migrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to create uuid extension: %w", err)
 	}
 
+	// enable earthdistance for geospatial property search and pg_trgm for
+	// destination autocomplete
+	for _, extension := range []string{"cube", "earthdistance", "pg_trgm"} {
+		err = db.Exec(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s", extension)).Error
+		if err != nil {
+			return fmt.Errorf("failed to create %s extension: %w", extension, err)
+		}
+	}
+
 	err = db.AutoMigrate(
 		&models.User{},
 		&models.Property{},
 		&models.Booking{},
 		&models.Review{},
+		&models.TaxRule{},
+		&models.SavedSearch{},
+		&models.Wishlist{},
+		&models.WishlistItem{},
+		&models.WishlistCollaborator{},
+		&models.Amenity{},
+		&models.PropertyImage{},
+		&models.PropertyModerationDecision{},
+		&models.PropertyRevision{},
+		&models.PropertyTranslation{},
+		&models.PropertyCoHost{},
+		&models.CoHostInvitation{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	err = migrateSearchVector(db)
+	if err != nil {
+		return fmt.Errorf("failed to migrate search vector: %w", err)
+	}
+
+	err = migrateTranslationSearchVector(db)
+	if err != nil {
+		return fmt.Errorf("failed to migrate translation search vector: %w", err)
+	}
+
+	err = seedAmenities(db)
+	if err != nil {
+		return fmt.Errorf("failed to seed amenities: %w", err)
+	}
+
+	err = migratePropertyAmenities(db)
+	if err != nil {
+		return fmt.Errorf("failed to migrate property amenities: %w", err)
+	}
+
+	err = migrateListingFieldAmenities(db)
+	if err != nil {
+		return fmt.Errorf("failed to migrate listing field amenities: %w", err)
+	}
+
+	err = backfillPropertyImages(db)
+	if err != nil {
+		return fmt.Errorf("failed to backfill property images: %w", err)
+	}
+
+	err = backfillModerationTimes(db)
+	if err != nil {
+		return fmt.Errorf("failed to backfill moderation times: %w", err)
+	}
+
+	err = backfillRatingSummaries(db)
+	if err != nil {
+		return fmt.Errorf("failed to backfill rating summaries: %w", err)
+	}
+
+	err = backfillPropertyRevisions(db)
+	if err != nil {
+		return fmt.Errorf("failed to backfill property revisions: %w", err)
+	}
+
 	err = createIndexes(db)
 	if err != nil {
 		return fmt.Errorf("failed to create indexes: %w", err)
@@ -62,6 +128,153 @@ func Migrate(db *gorm.DB) error {
 	return nil
 }
 
+// adds the full-text search column on properties. It is a generated column so
+// postgres keeps it current whenever a property is created or updated.
+func migrateSearchVector(db *gorm.DB) error {
+	return db.Exec(`
+		ALTER TABLE properties ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'B')
+		) STORED
+	`).Error
+}
+
+// adds the full-text search column on property translations, built with the
+// text search configuration of the language of each translation.
+// locale_search_config is also used to parse queries, see
+// translationMatchSQL; languages postgres has no stemmer for use 'simple'.
+func migrateTranslationSearchVector(db *gorm.DB) error {
+	err := db.Exec(`
+		CREATE OR REPLACE FUNCTION locale_search_config(locale text) RETURNS regconfig AS $$
+			SELECT (CASE lower(split_part(locale, '-', 1))
+				WHEN 'ar' THEN 'arabic'
+				WHEN 'da' THEN 'danish'
+				WHEN 'de' THEN 'german'
+				WHEN 'el' THEN 'greek'
+				WHEN 'en' THEN 'english'
+				WHEN 'es' THEN 'spanish'
+				WHEN 'fi' THEN 'finnish'
+				WHEN 'fr' THEN 'french'
+				WHEN 'hu' THEN 'hungarian'
+				WHEN 'id' THEN 'indonesian'
+				WHEN 'it' THEN 'italian'
+				WHEN 'nb' THEN 'norwegian'
+				WHEN 'nl' THEN 'dutch'
+				WHEN 'no' THEN 'norwegian'
+				WHEN 'pt' THEN 'portuguese'
+				WHEN 'ro' THEN 'romanian'
+				WHEN 'ru' THEN 'russian'
+				WHEN 'sv' THEN 'swedish'
+				WHEN 'tr' THEN 'turkish'
+				ELSE 'simple'
+			END)::regconfig
+		$$ LANGUAGE sql IMMUTABLE
+	`).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		ALTER TABLE property_translations ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector(locale_search_config(locale), coalesce(title, '')), 'A') ||
+			setweight(to_tsvector(locale_search_config(locale), coalesce(description, '')), 'B')
+		) STORED
+	`).Error
+}
+
+// adds image rows for the URLs of properties created before images were
+// tracked on their own. The first URL becomes the cover.
+func backfillPropertyImages(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO property_images (property_id, url, position, is_cover, created_at, updated_at)
+		SELECT p.id, u.url, u.ord - 1, u.ord = 1, NOW(), NOW()
+		FROM properties p, unnest(p.images) WITH ORDINALITY AS u(url, ord)
+		WHERE NOT EXISTS (SELECT 1 FROM property_images i WHERE i.property_id = p.id)
+	`).Error
+}
+
+// dates the submission of listings created before it was tracked, and the
+// approval of those that have been live
+func backfillModerationTimes(db *gorm.DB) error {
+	err := db.Exec(`
+		UPDATE properties SET submitted_at = created_at
+		WHERE submitted_at IS NULL AND status <> 'draft'
+	`).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		UPDATE properties SET approved_at = created_at
+		WHERE approved_at IS NULL AND status IN ('active', 'inactive')
+	`).Error
+}
+
+// fills in the denormalized rating of properties reviewed before it was tracked
+func backfillRatingSummaries(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE properties SET
+			average_rating = summary.average_rating,
+			review_count = summary.review_count
+		FROM (
+			SELECT property_id, AVG(rating) AS average_rating, COUNT(*) AS review_count
+			FROM reviews
+			WHERE deleted_at IS NULL
+			GROUP BY property_id
+		) AS summary
+		WHERE properties.id = summary.property_id AND properties.review_count = 0
+	`).Error
+}
+
+// gives properties created before revisions were kept a first revision of
+// how they look now, attributed to their host. The snapshot matches
+// models.PropertySnapshot.
+func backfillPropertyRevisions(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO property_revisions (property_id, number, action, changed_fields, actor_id, snapshot, created_at)
+		SELECT p.id, 1, 'created', '{}', p.host_id, jsonb_build_object(
+			'title', p.title,
+			'description', COALESCE(p.description, ''),
+			'type', p.type,
+			'status', p.status,
+			'price_per_night', p.price_per_night,
+			'currency', COALESCE(p.currency, ''),
+			'max_guests', p.max_guests,
+			'bedrooms', p.bedrooms,
+			'bathrooms', p.bathrooms,
+			'rooms', COALESCE(p.rooms, '[]'),
+			'address', p.address,
+			'city', p.city,
+			'state', p.state,
+			'country', p.country,
+			'zip_code', p.zip_code,
+			'latitude', COALESCE(p.latitude, 0),
+			'longitude', COALESCE(p.longitude, 0),
+			'amenities', COALESCE(to_jsonb(p.amenities), '[]'),
+			'images', COALESCE(to_jsonb(p.images), '[]'),
+			'rules', COALESCE(to_jsonb(p.rules), '[]'),
+			'house_rules', jsonb_build_object(
+				'pets_allowed', p.pets_allowed,
+				'smoking_allowed', p.smoking_allowed,
+				'events_allowed', p.events_allowed,
+				'children_allowed', p.children_allowed,
+				'infants_allowed', p.infants_allowed,
+				'quiet_hours_start', COALESCE(p.quiet_hours_start, ''),
+				'quiet_hours_end', COALESCE(p.quiet_hours_end, '')
+			),
+			'accessibility_features', COALESCE(to_jsonb(p.accessibility_features), '[]'),
+			'accessibility_notes', COALESCE(p.accessibility_notes, ''),
+			'check_in_time', COALESCE(to_char(p.check_in_time, 'HH24:MI:SS'), '00:00:00'),
+			'check_out_time', COALESCE(to_char(p.check_out_time, 'HH24:MI:SS'), '00:00:00')
+		), p.updated_at
+		FROM properties p
+		WHERE p.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM property_revisions r WHERE r.property_id = p.id)
+	`).Error
+}
+
 // creates additional indexes for better performance
 func createIndexes(db *gorm.DB) error {
 	// property indexes
@@ -70,6 +283,30 @@ func createIndexes(db *gorm.DB) error {
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_type ON properties (type)",
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_status ON properties (status)",
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_host_id ON properties (host_id)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_earth ON properties USING gist (ll_to_earth(latitude::float8, longitude::float8))",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_lat_lng ON properties (latitude, longitude)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_search_vector ON properties USING gin (search_vector)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_price ON properties (price_per_night)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_rating ON properties (average_rating DESC, review_count DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_created_at ON properties (created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_city_trgm ON properties USING gin (LOWER(city) gin_trgm_ops)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_state_trgm ON properties USING gin (LOWER(state) gin_trgm_ops)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_country_trgm ON properties USING gin (LOWER(country) gin_trgm_ops)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_amenities ON properties USING gin (amenities)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_accessibility ON properties USING gin (accessibility_features)",
+
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_moderation_queue ON properties ((COALESCE(submitted_at, created_at)), id) WHERE status = 'pending'",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_pending_changes ON properties (id) WHERE pending_changes IS NOT NULL",
+
+		// property image indexes
+		"CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_property_images_cover ON property_images (property_id) WHERE is_cover",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_property_images_position ON property_images (property_id, position)",
+
+		// property translation indexes
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_property_translations_search_vector ON property_translations USING gin (search_vector)",
+
+		// property revision indexes
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_property_revisions_property_created ON property_revisions (property_id, created_at DESC, id DESC)",
 		
 		// booking indexes
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_bookings_dates ON bookings (check_in, check_out)",
@@ -77,11 +314,33 @@ func createIndexes(db *gorm.DB) error {
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_bookings_guest_id ON bookings (guest_id)",
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_bookings_status ON bookings (status)",
 		
+		// keyset pagination indexes
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_status_created ON properties (status, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_host_created ON properties (host_id, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_bookings_guest_created ON bookings (guest_id, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_bookings_property_created ON bookings (property_id, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_reviews_property_created ON reviews (property_id, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_reviews_reviewer_created ON reviews (reviewer_id, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tax_rules_created ON tax_rules (created_at DESC, id DESC)",
+
 		// review indexes
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_reviews_property_id ON reviews (property_id)",
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_reviews_reviewer_id ON reviews (reviewer_id)",
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_reviews_rating ON reviews (rating)",
+
+		// tax rule indexes
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_tax_rules_jurisdiction ON tax_rules (LOWER(country), LOWER(state), LOWER(city))",
 		
+		// saved search indexes
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_saved_searches_user_created ON saved_searches (user_id, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_saved_searches_alerts ON saved_searches (id) WHERE alerts_enabled AND deleted_at IS NULL",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_bookings_cancelled_updated ON bookings (property_id, updated_at) WHERE status = 'cancelled'",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_properties_updated_at ON properties (updated_at)",
+
+		// wishlist indexes
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_wishlists_owner_created ON wishlists (owner_id, created_at DESC, id DESC)",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_wishlist_items_property_id ON wishlist_items (property_id)",
+
 		// user indexes
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_email ON users (email)",
 		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_role ON users (role)",
@@ -0,0 +1,172 @@
+// Package media checks uploaded images and renders their resized variants
+// using only the standard library decoders.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// largest image accepted, in pixels. A decoded image takes four bytes per
+// pixel, so this keeps a single upload to about 100MB of memory.
+const MaxPixels = 25_000_000
+
+// quality of the JPEG variants
+const jpegQuality = 85
+
+// image types accepted for upload, with the file extension they are stored with
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Variant is a resized copy rendered for every uploaded image
+type Variant struct {
+	Name  string
+	Width int
+}
+
+// Variants are rendered for every upload, smallest first
+var Variants = []Variant{
+	{Name: "thumb", Width: 320},
+	{Name: "medium", Width: 1024},
+}
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrInvalidImage    = errors.New("invalid image")
+	ErrTooManyPixels   = errors.New("image dimensions are too large")
+)
+
+// Image is a decoded upload
+type Image struct {
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+	img         image.Image
+	// img drawn over white, rendered on the first resize
+	flat *image.RGBA
+}
+
+// Decode checks the content of data rather than trusting the file name or
+// the declared content type, and decodes it. The dimensions are checked
+// before the pixels are decoded. JPEGs are turned upright according to their
+// EXIF orientation, so Width and Height are those of the upright image.
+func Decode(data []byte) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	extension, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	var img image.Image
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		img, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	return &Image{
+		ContentType: contentType,
+		Extension:   extension,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		img:         img,
+	}, nil
+}
+
+// Resize returns a JPEG of the image scaled down to width, keeping the
+// aspect ratio. Images that are already narrower keep their size.
+// Transparent areas are filled with white.
+func (i *Image) Resize(width int) (data []byte, w, h int, err error) {
+	w, h = i.Width, i.Height
+	if width > 0 && w > width {
+		h = max(1, h*width/w)
+		w = width
+	}
+
+	if i.flat == nil {
+		i.flat = image.NewRGBA(image.Rect(0, 0, i.Width, i.Height))
+		draw.Draw(i.flat, i.flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(i.flat, i.flat.Bounds(), i.img, i.img.Bounds().Min, draw.Over)
+	}
+	src := i.flat
+
+	dst := src
+	if w != i.Width || h != i.Height {
+		dst = scaleDown(src, w, h)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), w, h, nil
+}
+
+// scaleDown shrinks src to w by h by averaging the source pixels that fall in
+// each destination pixel, which avoids the aliasing of nearest neighbour
+func scaleDown(src *image.RGBA, w, h int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := y * srcH / h
+		y1 := max(y0+1, (y+1)*srcH/h)
+		for x := 0; x < w; x++ {
+			x0 := x * srcW / w
+			x1 := max(x0+1, (x+1)*srcW/w)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4 : y*dst.Stride+x*4+4]
+			d[0] = uint8(r / n)
+			d[1] = uint8(g / n)
+			d[2] = uint8(b / n)
+			d[3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql/driver"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// DefaultLocale is used when an amenity has no name in the requested locale
+const DefaultLocale = "en"
+
+// ids of former amenities that listings state through their house rules and
+// accessibility features instead, so that search filters have one source
+const (
+	AmenityPetsAllowed = "pets_allowed"
+	AmenityElevator    = "elevator"
+)
+
+// IsListingFieldAmenity reports whether id may not be used for an amenity
+// because listings state it in a field of their own
+func IsListingFieldAmenity(id string) bool {
+	return id == AmenityPetsAllowed || id == AmenityElevator
+}
+
+// LocalizedNames maps a locale such as "en" or "pt-BR" to a display name
+type LocalizedNames map[string]string
+
+func (n LocalizedNames) Value() (driver.Value, error) {
+	return jsonValue(n)
+}
+
+func (n *LocalizedNames) Scan(src interface{}) error {
+	return scanJSON(src, n)
+}
+
+// Name returns the name for locale, falling back to its base language, then
+// to the default locale
+func (n LocalizedNames) Name(locale string) string {
+	if name, ok := n[locale]; ok {
+		return name
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		if name, ok := n[base]; ok {
+			return name
+		}
+	}
+	return n[DefaultLocale]
+}
+
+// Amenity is an entry of the amenity catalog. Properties list amenities by
+// ID. Aliases are other spellings, e.g. "Wi-Fi" for "wifi", that are mapped to
+// the amenity when hosts or searches use them.
+type Amenity struct {
+	ID        string         `json:"id" gorm:"type:varchar(50);primaryKey" validate:"required,max=50"`
+	Category  string         `json:"category" gorm:"type:varchar(50);not null;index" validate:"required"`
+	Icon      string         `json:"icon"`
+	Names     LocalizedNames `json:"names" gorm:"type:jsonb;not null"`
+	Aliases   pq.StringArray `json:"aliases" gorm:"type:text[]"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type AmenityCreateRequest struct {
+	ID       string         `json:"id" validate:"required,max=50"`
+	Category string         `json:"category" validate:"required"`
+	Icon     string         `json:"icon"`
+	Names    LocalizedNames `json:"names" validate:"required"`
+	Aliases  []string       `json:"aliases"`
+}
+
+type AmenityUpdateRequest struct {
+	Category string         `json:"category,omitempty"`
+	Icon     *string        `json:"icon,omitempty"`
+	Names    LocalizedNames `json:"names,omitempty"`
+	Aliases  []string       `json:"aliases,omitempty"`
+}
+
+type AmenityResponse struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Icon     string `json:"icon"`
+	Name     string `json:"name"`
+}
+
+// UnmappedAmenity is a free-text amenity of older listings that matches no
+// catalog entry
+type UnmappedAmenity struct {
+	Value         string `json:"value"`
+	PropertyCount int64  `json:"property_count"`
+}
+
+func (Amenity) TableName() string {
+	return "amenities"
+}
+
+// converts Amenity to AmenityResponse with its name in the given locale
+func (a *Amenity) ToResponse(locale string) *AmenityResponse {
+	name := a.Names.Name(locale)
+	if name == "" {
+		name = a.ID
+	}
+
+	return &AmenityResponse{
+		ID:       a.ID,
+		Category: a.Category,
+		Icon:     a.Icon,
+		Name:     name,
+	}
+}
+
+// AmenityKey reduces an amenity spelling to the form aliases are compared in,
+// so "Wi-Fi", "WiFi" and "wifi" all become "wifi"
+func AmenityKey(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
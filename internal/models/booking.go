@@ -17,21 +17,25 @@ const (
 )
 
 type Booking struct {
-	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	PropertyID uuid.UUID      `json:"property_id" gorm:"type:uuid;not null"`
-	GuestID    uuid.UUID      `json:"guest_id" gorm:"type:uuid;not null"`
-	CheckIn    time.Time      `json:"check_in" gorm:"not null" validate:"required"`
-	CheckOut   time.Time      `json:"check_out" gorm:"not null" validate:"required"`
-	Guests     int            `json:"guests" gorm:"not null" validate:"required,min=1"`
-	TotalPrice float64        `json:"total_price" gorm:"not null" validate:"required,min=0"`
-	Currency   string         `json:"currency" gorm:"default:'USD'"`
-	Status     BookingStatus  `json:"status" gorm:"type:varchar(20);default:'pending'" validate:"required,oneof=pending confirmed cancelled completed"`
-	Notes      string         `json:"notes" gorm:"type:text"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
-	Property   Property       `json:"property,omitzero" gorm:"foreignKey:PropertyID"`
-	Guest      User           `json:"guest,omitzero" gorm:"foreignKey:GuestID"`
+	ID                 uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PropertyID         uuid.UUID      `json:"property_id" gorm:"type:uuid;not null"`
+	PropertyRevisionID *uuid.UUID     `json:"property_revision_id,omitempty" gorm:"type:uuid"`
+	GuestID            uuid.UUID      `json:"guest_id" gorm:"type:uuid;not null"`
+	CheckIn            time.Time      `json:"check_in" gorm:"not null" validate:"required"`
+	CheckOut           time.Time      `json:"check_out" gorm:"not null" validate:"required"`
+	Guests             int            `json:"guests" gorm:"not null" validate:"required,min=1"`
+	Subtotal           float64        `json:"subtotal" gorm:"not null;default:0"`
+	TaxAmount          float64        `json:"tax_amount" gorm:"not null;default:0"`
+	Taxes              BookingTaxes   `json:"taxes" gorm:"type:jsonb;default:'[]'"`
+	TotalPrice         float64        `json:"total_price" gorm:"not null" validate:"required,min=0"`
+	Currency           string         `json:"currency" gorm:"default:'USD'"`
+	Status             BookingStatus  `json:"status" gorm:"type:varchar(20);default:'pending'" validate:"required,oneof=pending confirmed cancelled completed"`
+	Notes              string         `json:"notes" gorm:"type:text"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+	Property           Property       `json:"property,omitzero" gorm:"foreignKey:PropertyID"`
+	Guest              User           `json:"guest,omitzero" gorm:"foreignKey:GuestID"`
 }
 
 type BookingCreateRequest struct {
@@ -51,41 +55,63 @@ type BookingUpdateRequest struct {
 }
 
 type BookingResponse struct {
-	ID         uuid.UUID         `json:"id"`
-	PropertyID uuid.UUID         `json:"property_id"`
-	GuestID    uuid.UUID         `json:"guest_id"`
-	CheckIn    time.Time         `json:"check_in"`
-	CheckOut   time.Time         `json:"check_out"`
-	Guests     int               `json:"guests"`
-	TotalPrice float64           `json:"total_price"`
-	Currency   string            `json:"currency"`
-	Status     BookingStatus     `json:"status"`
-	Notes      string            `json:"notes"`
-	CreatedAt  time.Time         `json:"created_at"`
-	UpdatedAt  time.Time         `json:"updated_at"`
-	Property   *PropertyResponse `json:"property,omitempty"`
-	Guest      *UserResponse     `json:"guest,omitempty"`
+	ID                 uuid.UUID         `json:"id"`
+	PropertyID         uuid.UUID         `json:"property_id"`
+	PropertyRevisionID *uuid.UUID        `json:"property_revision_id,omitempty"`
+	GuestID            uuid.UUID         `json:"guest_id"`
+	CheckIn            time.Time         `json:"check_in"`
+	CheckOut           time.Time         `json:"check_out"`
+	Guests             int               `json:"guests"`
+	Status             BookingStatus     `json:"status"`
+	Notes              string            `json:"notes"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	Property           *PropertyResponse `json:"property,omitempty"`
+	Guest              *UserResponse     `json:"guest,omitempty"`
+
+	*BookingPrice
+}
+
+// BookingPrice is what a booking costs the guest. Responses leave it out for
+// co-hosts who may not see earnings.
+type BookingPrice struct {
+	Subtotal   float64      `json:"subtotal"`
+	TaxAmount  float64      `json:"tax_amount"`
+	Taxes      BookingTaxes `json:"taxes"`
+	TotalPrice float64      `json:"total_price"`
+	Currency   string       `json:"currency"`
 }
 
 func (Booking) TableName() string {
 	return "bookings"
 }
 
+// PageCursor returns the position of the booking in paginated lists
+func (b *Booking) PageCursor() Cursor {
+	return Cursor{CreatedAt: b.CreatedAt, ID: b.ID}
+}
+
 // ToResponse converts Booking to BookingResponse
 func (b *Booking) ToResponse() *BookingResponse {
 	response := &BookingResponse{
-		ID:         b.ID,
-		PropertyID: b.PropertyID,
-		GuestID:    b.GuestID,
-		CheckIn:    b.CheckIn,
-		CheckOut:   b.CheckOut,
-		Guests:     b.Guests,
-		TotalPrice: b.TotalPrice,
-		Currency:   b.Currency,
-		Status:     b.Status,
-		Notes:      b.Notes,
-		CreatedAt:  b.CreatedAt,
-		UpdatedAt:  b.UpdatedAt,
+		ID:                 b.ID,
+		PropertyID:         b.PropertyID,
+		PropertyRevisionID: b.PropertyRevisionID,
+		GuestID:            b.GuestID,
+		CheckIn:            b.CheckIn,
+		CheckOut:           b.CheckOut,
+		Guests:             b.Guests,
+		Status:             b.Status,
+		Notes:              b.Notes,
+		CreatedAt:          b.CreatedAt,
+		UpdatedAt:          b.UpdatedAt,
+		BookingPrice: &BookingPrice{
+			Subtotal:   b.Subtotal,
+			TaxAmount:  b.TaxAmount,
+			Taxes:      b.Taxes,
+			TotalPrice: b.TotalPrice,
+			Currency:   b.Currency,
+		},
 	}
 
 	if b.Property.ID != uuid.Nil {
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type CoHostPermission string
+
+const (
+	// change the listing, its photos, translations and revisions
+	CoHostPermissionEditListing CoHostPermission = "edit_listing"
+	// see which dates are booked, without the guests or prices
+	CoHostPermissionManageCalendar CoHostPermission = "manage_calendar"
+	// see, confirm, complete and cancel bookings, without their prices
+	CoHostPermissionRespondToBookings CoHostPermission = "respond_to_bookings"
+	// see receipts, booking prices and how much the listing earns
+	CoHostPermissionViewEarnings CoHostPermission = "view_earnings"
+)
+
+// IsValidCoHostPermission reports whether p is a supported co-host permission
+func IsValidCoHostPermission(p CoHostPermission) bool {
+	switch p {
+	case CoHostPermissionEditListing, CoHostPermissionManageCalendar, CoHostPermissionRespondToBookings, CoHostPermissionViewEarnings:
+		return true
+	}
+	return false
+}
+
+// PropertyCoHost is a user who helps the host manage a property with the
+// given permissions. Only the host can delete the property or change its
+// co-hosts.
+type PropertyCoHost struct {
+	PropertyID  uuid.UUID      `json:"property_id" gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID      `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Permissions pq.StringArray `json:"permissions" gorm:"type:text[];not null"`
+	InvitedByID uuid.UUID      `json:"invited_by_id" gorm:"type:uuid;not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	User        User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// CoHostInvitation is sent by email to someone the host wants as a co-host.
+// Only a hash of the token is stored; the token itself is in the email.
+type CoHostInvitation struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PropertyID  uuid.UUID      `json:"property_id" gorm:"type:uuid;not null;index"`
+	Email       string         `json:"email" gorm:"not null"`
+	Permissions pq.StringArray `json:"permissions" gorm:"type:text[];not null"`
+	TokenHash   string         `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	InvitedByID uuid.UUID      `json:"invited_by_id" gorm:"type:uuid;not null"`
+	ExpiresAt   time.Time      `json:"expires_at" gorm:"not null"`
+	AcceptedAt  *time.Time     `json:"accepted_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Property    Property       `json:"property,omitempty" gorm:"foreignKey:PropertyID"`
+}
+
+type CoHostInvitationRequest struct {
+	Email       string   `json:"email" validate:"required,email"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+type CoHostUpdateRequest struct {
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+type CoHostAcceptRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type CoHostResponse struct {
+	PropertyID  uuid.UUID     `json:"property_id"`
+	UserID      uuid.UUID     `json:"user_id"`
+	Permissions []string      `json:"permissions"`
+	CreatedAt   time.Time     `json:"created_at"`
+	User        *UserResponse `json:"user,omitempty"`
+}
+
+type CoHostInvitationResponse struct {
+	ID          uuid.UUID `json:"id"`
+	PropertyID  uuid.UUID `json:"property_id"`
+	Email       string    `json:"email"`
+	Permissions []string  `json:"permissions"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (PropertyCoHost) TableName() string {
+	return "property_cohosts"
+}
+
+func (CoHostInvitation) TableName() string {
+	return "cohost_invitations"
+}
+
+// Can reports whether the co-host has any of the permissions
+func (c *PropertyCoHost) Can(permissions ...CoHostPermission) bool {
+	for _, granted := range c.Permissions {
+		for _, permission := range permissions {
+			if CoHostPermission(granted) == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *PropertyCoHost) ToResponse() *CoHostResponse {
+	response := &CoHostResponse{
+		PropertyID:  c.PropertyID,
+		UserID:      c.UserID,
+		Permissions: c.Permissions,
+		CreatedAt:   c.CreatedAt,
+	}
+
+	if c.User.ID != uuid.Nil {
+		response.User = c.User.ToResponse()
+	}
+
+	return response
+}
+
+func (i *CoHostInvitation) ToResponse() *CoHostInvitationResponse {
+	return &CoHostInvitationResponse{
+		ID:          i.ID,
+		PropertyID:  i.PropertyID,
+		Email:       i.Email,
+		Permissions: i.Permissions,
+		ExpiresAt:   i.ExpiresAt,
+		CreatedAt:   i.CreatedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HostAnalyticsRequest covers the nights from From up to and including To,
+// for one property or, when PropertyID is nil, every property whose earnings
+// the host can see
+type HostAnalyticsRequest struct {
+	From       time.Time
+	To         time.Time
+	PropertyID *uuid.UUID
+}
+
+// CurrencyRevenue is what stays in one currency earned during the period.
+// Revenue excludes taxes and counts the part of each stay inside the period.
+type CurrencyRevenue struct {
+	Currency         string  `json:"currency"`
+	BookedNights     int64   `json:"booked_nights"`
+	Revenue          float64 `json:"revenue"`
+	AverageDailyRate float64 `json:"average_daily_rate"`
+}
+
+// Occupancy counts the nights listings were live during the period and how
+// many of them were booked
+type Occupancy struct {
+	AvailableNights int64
+	BookedNights    int64
+}
+
+// BookingActivity counts the bookings made during the period. The lead time
+// is the days between booking and check-in of those not cancelled.
+type BookingActivity struct {
+	Bookings          int64
+	CancelledBookings int64
+	AverageLeadDays   float64
+}
+
+// RatingTrendPoint is the average rating of the reviews left in one month
+type RatingTrendPoint struct {
+	Month         time.Time `json:"month"`
+	AverageRating float64   `json:"average_rating"`
+	ReviewCount   int64     `json:"review_count"`
+}
+
+// HostAnalytics is how the properties of a host performed over a period.
+// Booked nights come from confirmed and completed stays; nights are
+// available from the day a listing was approved, and none are for listings
+// that are inactive now. Available and booked nights, and so the occupancy
+// rate, cover the same listings; revenue covers every stay.
+type HostAnalytics struct {
+	From              time.Time           `json:"from"`
+	To                time.Time           `json:"to"`
+	PropertyID        *uuid.UUID          `json:"property_id,omitempty"`
+	PropertyCount     int                 `json:"property_count"`
+	AvailableNights   int64               `json:"available_nights"`
+	BookedNights      int64               `json:"booked_nights"`
+	OccupancyRate     float64             `json:"occupancy_rate"`
+	Revenue           []*CurrencyRevenue  `json:"revenue"`
+	Bookings          int64               `json:"bookings"`
+	CancelledBookings int64               `json:"cancelled_bookings"`
+	CancellationRate  float64             `json:"cancellation_rate"`
+	AverageLeadDays   float64             `json:"average_lead_time_days"`
+	RatingTrend       []*RatingTrendPoint `json:"rating_trend"`
+}
@@ -0,0 +1,52 @@
+package models
+
+// HouseRules are the rules of a listing guests can filter searches on. A rule
+// left nil was not stated by the host, so it matches neither "allowed" nor
+// "not allowed". Anything else the host asks of guests stays in the free-text
+// Property.Rules.
+type HouseRules struct {
+	PetsAllowed    *bool `json:"pets_allowed"`
+	SmokingAllowed *bool `json:"smoking_allowed"`
+	EventsAllowed  *bool `json:"events_allowed"`
+	// children from 2 to 12 years old
+	ChildrenAllowed *bool `json:"children_allowed"`
+	// infants under 2 years old
+	InfantsAllowed *bool `json:"infants_allowed"`
+	// "15:04" in the local time of the listing, both set or both empty.
+	// The end is before the start when quiet hours run past midnight.
+	QuietHoursStart string `json:"quiet_hours_start" gorm:"type:varchar(5);not null;default:''"`
+	QuietHoursEnd   string `json:"quiet_hours_end" gorm:"type:varchar(5);not null;default:''"`
+}
+
+// time of day format of quiet hours
+const QuietHoursFormat = "15:04"
+
+type AccessibilityFeature string
+
+const (
+	AccessibilityStepFreeEntrance  AccessibilityFeature = "step_free_entrance"
+	AccessibilityStepFreePath      AccessibilityFeature = "step_free_path"
+	AccessibilityWideDoorways      AccessibilityFeature = "wide_doorways"
+	AccessibilityAccessibleParking AccessibilityFeature = "accessible_parking"
+	AccessibilityElevator          AccessibilityFeature = "elevator"
+	AccessibilityStepFreeBedroom   AccessibilityFeature = "step_free_bedroom"
+	AccessibilityStepFreeShower    AccessibilityFeature = "step_free_shower"
+	AccessibilityShowerChair       AccessibilityFeature = "shower_chair"
+	AccessibilityGrabBars          AccessibilityFeature = "grab_bars"
+	AccessibilityBedHeight         AccessibilityFeature = "accessible_bed_height"
+	AccessibilityToiletHeight      AccessibilityFeature = "accessible_toilet_height"
+	AccessibilityCeilingHoist      AccessibilityFeature = "ceiling_hoist"
+)
+
+// IsValidAccessibilityFeature reports whether f is a supported accessibility
+// feature
+func IsValidAccessibilityFeature(f AccessibilityFeature) bool {
+	switch f {
+	case AccessibilityStepFreeEntrance, AccessibilityStepFreePath, AccessibilityWideDoorways,
+		AccessibilityAccessibleParking, AccessibilityElevator, AccessibilityStepFreeBedroom,
+		AccessibilityStepFreeShower, AccessibilityShowerChair, AccessibilityGrabBars,
+		AccessibilityBedHeight, AccessibilityToiletHeight, AccessibilityCeilingHoist:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package models
+
+import (
+	"database/sql/driver"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ModerationDecisionType string
+
+const (
+	ModerationDecisionApproved ModerationDecisionType = "approved"
+	ModerationDecisionRejected ModerationDecisionType = "rejected"
+)
+
+// RejectionReason is a reason code a moderator can give when rejecting a
+// listing, with the text shown to the host
+type RejectionReason struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// RejectionReasons are the reason codes accepted when rejecting a listing
+var RejectionReasons = []RejectionReason{
+	{Code: "incomplete_information", Label: "Some information about the listing is missing"},
+	{Code: "inaccurate_location", Label: "The address or map location is inaccurate"},
+	{Code: "low_quality_photos", Label: "The photos are missing, blurry or do not show the property"},
+	{Code: "misleading_description", Label: "The title or description is misleading"},
+	{Code: "prohibited_content", Label: "The listing contains prohibited content"},
+	{Code: "pricing_issue", Label: "The price looks wrong"},
+	{Code: "duplicate_listing", Label: "The property is already listed"},
+	{Code: "policy_violation", Label: "The listing breaks our hosting policies"},
+	{Code: RejectionReasonOther, Label: "Other, see the moderator notes"},
+}
+
+// reason code that needs notes explaining it
+const RejectionReasonOther = "other"
+
+// LookupRejectionReason returns the reason with the code
+func LookupRejectionReason(code string) (RejectionReason, bool) {
+	for _, reason := range RejectionReasons {
+		if reason.Code == code {
+			return reason, true
+		}
+	}
+	return RejectionReason{}, false
+}
+
+// PropertyModerationDecision records a moderator approving or rejecting a
+// listing. Decisions are never changed, they form the audit trail of the
+// listing. Changes holds what was reviewed when the listing was already
+// approved and only its changes were up for review.
+type PropertyModerationDecision struct {
+	ID          uuid.UUID              `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PropertyID  uuid.UUID              `json:"property_id" gorm:"type:uuid;not null;index"`
+	ReviewerID  uuid.UUID              `json:"reviewer_id" gorm:"type:uuid;not null"`
+	Decision    ModerationDecisionType `json:"decision" gorm:"type:varchar(20);not null"`
+	ReasonCodes pq.StringArray         `json:"reason_codes" gorm:"type:text[]"`
+	Notes       string                 `json:"notes" gorm:"type:text"`
+	Changes     *PropertyChanges       `json:"changes,omitempty" gorm:"type:jsonb"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Reviewer    User                   `json:"-" gorm:"foreignKey:ReviewerID"`
+}
+
+// PropertyChanges are material changes a host made to an approved listing.
+// They wait for a moderator while the listing stays live as approved. Nil
+// fields are unchanged.
+type PropertyChanges struct {
+	Type      *PropertyType `json:"type,omitempty"`
+	Address   *string       `json:"address,omitempty"`
+	City      *string       `json:"city,omitempty"`
+	State     *string       `json:"state,omitempty"`
+	Country   *string       `json:"country,omitempty"`
+	ZipCode   *string       `json:"zip_code,omitempty"`
+	Latitude  *float64      `json:"latitude,omitempty"`
+	Longitude *float64      `json:"longitude,omitempty"`
+	// replaces the image URLs when approved
+	Images []string `json:"images,omitempty"`
+	// photos were uploaded that are not shown until approved
+	NewImages bool `json:"new_images,omitempty"`
+	// the uploaded photos, changes staged before they were listed cover
+	// every photo waiting for review
+	NewImageIDs []uuid.UUID `json:"new_image_ids,omitempty"`
+	RequestedAt *time.Time  `json:"requested_at,omitempty"`
+	// counts the merges, so a moderator decides on the changes they saw
+	Version int `json:"version"`
+}
+
+func (c PropertyChanges) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+func (c *PropertyChanges) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// IsEmpty reports whether nothing is changed
+func (c *PropertyChanges) IsEmpty() bool {
+	return c.Type == nil && c.Address == nil && c.City == nil && c.State == nil &&
+		c.Country == nil && c.ZipCode == nil && c.Latitude == nil && c.Longitude == nil &&
+		c.Images == nil && !c.NewImages
+}
+
+// Without drops the changes that match the current values of the property
+func (c *PropertyChanges) Without(p *Property) *PropertyChanges {
+	changes := *c
+	if changes.Type != nil && *changes.Type == p.Type {
+		changes.Type = nil
+	}
+	dropSame := func(value **string, current string) {
+		if *value != nil && **value == current {
+			*value = nil
+		}
+	}
+	dropSame(&changes.Address, p.Address)
+	dropSame(&changes.City, p.City)
+	dropSame(&changes.State, p.State)
+	dropSame(&changes.Country, p.Country)
+	dropSame(&changes.ZipCode, p.ZipCode)
+	if changes.Latitude != nil && *changes.Latitude == p.Latitude {
+		changes.Latitude = nil
+	}
+	if changes.Longitude != nil && *changes.Longitude == p.Longitude {
+		changes.Longitude = nil
+	}
+	if changes.Images != nil && slices.Equal(changes.Images, p.Images) {
+		changes.Images = nil
+	}
+	return &changes
+}
+
+// Merge returns the changes with newer applied on top
+func (c *PropertyChanges) Merge(newer *PropertyChanges) *PropertyChanges {
+	merged := PropertyChanges{}
+	if c != nil {
+		merged = *c
+	}
+	if newer.Type != nil {
+		merged.Type = newer.Type
+	}
+	if newer.Address != nil {
+		merged.Address = newer.Address
+	}
+	if newer.City != nil {
+		merged.City = newer.City
+	}
+	if newer.State != nil {
+		merged.State = newer.State
+	}
+	if newer.Country != nil {
+		merged.Country = newer.Country
+	}
+	if newer.ZipCode != nil {
+		merged.ZipCode = newer.ZipCode
+	}
+	if newer.Latitude != nil {
+		merged.Latitude = newer.Latitude
+	}
+	if newer.Longitude != nil {
+		merged.Longitude = newer.Longitude
+	}
+	if newer.Images != nil {
+		merged.Images = newer.Images
+	}
+	merged.NewImages = merged.NewImages || newer.NewImages
+	merged.NewImageIDs = append(slices.Clone(merged.NewImageIDs), newer.NewImageIDs...)
+	merged.Version++
+	if merged.RequestedAt == nil {
+		merged.RequestedAt = newer.RequestedAt
+	}
+	return &merged
+}
+
+// ApplyTo sets the changed fields on the property. Images are left to the
+// caller since they live in their own table.
+func (c *PropertyChanges) ApplyTo(p *Property) {
+	if c.Type != nil {
+		p.Type = *c.Type
+	}
+	if c.Address != nil {
+		p.Address = *c.Address
+	}
+	if c.City != nil {
+		p.City = *c.City
+	}
+	if c.State != nil {
+		p.State = *c.State
+	}
+	if c.Country != nil {
+		p.Country = *c.Country
+	}
+	if c.ZipCode != nil {
+		p.ZipCode = *c.ZipCode
+	}
+	if c.Latitude != nil {
+		p.Latitude = *c.Latitude
+	}
+	if c.Longitude != nil {
+		p.Longitude = *c.Longitude
+	}
+}
+
+type PropertyApproveRequest struct {
+	Notes string `json:"notes"`
+	// the version of the pending changes the moderator reviewed, as shown in
+	// the queue
+	ChangesVersion *int `json:"changes_version,omitempty"`
+}
+
+type PropertyRejectRequest struct {
+	ReasonCodes    []string `json:"reason_codes" validate:"required,min=1"`
+	Notes          string   `json:"notes"`
+	ChangesVersion *int     `json:"changes_version,omitempty"`
+}
+
+// ModerationQueueRequest filters the new listings and changes to approved
+// listings waiting for a moderator
+type ModerationQueueRequest struct {
+	Query   string     `form:"q"`
+	City    string     `form:"city"`
+	Country string     `form:"country"`
+	Type    string     `form:"type"`
+	HostID  *uuid.UUID `form:"-"`
+	// only changes to approved listings when true, only new listings when false
+	Changes *bool `form:"-"`
+	// only listings that were rejected before when true, only new ones when false
+	Resubmitted *bool `form:"-"`
+	Page        int   `form:"page"`
+	Limit       int   `form:"limit"`
+}
+
+type ModerationDecisionResponse struct {
+	ID       uuid.UUID              `json:"id"`
+	Decision ModerationDecisionType `json:"decision"`
+	Reasons  []RejectionReason      `json:"reasons"`
+	Notes    string                 `json:"notes"`
+	Changes  *PropertyChanges       `json:"changes,omitempty"`
+	// only shown to admins
+	Reviewer  *UserResponse `json:"reviewer,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ModerationQueueItem is a listing waiting for approval
+type ModerationQueueItem struct {
+	*PropertyResponse
+	PreviousRejections int `json:"previous_rejections"`
+}
+
+type ModerationQueueResponse struct {
+	Properties []*ModerationQueueItem `json:"properties"`
+	Total      int64                  `json:"total"`
+	Page       int                    `json:"page"`
+	Limit      int                    `json:"limit"`
+	TotalPages int                    `json:"total_pages"`
+}
+
+func (PropertyModerationDecision) TableName() string {
+	return "property_moderation_decisions"
+}
+
+// ToResponse converts the decision, naming the reviewer only when
+// withReviewer is set
+func (d *PropertyModerationDecision) ToResponse(withReviewer bool) *ModerationDecisionResponse {
+	reasons := make([]RejectionReason, 0, len(d.ReasonCodes))
+	for _, code := range d.ReasonCodes {
+		reason, ok := LookupRejectionReason(code)
+		if !ok {
+			// a code that was retired since
+			reason = RejectionReason{Code: code, Label: code}
+		}
+		reasons = append(reasons, reason)
+	}
+
+	response := &ModerationDecisionResponse{
+		ID:        d.ID,
+		Decision:  d.Decision,
+		Reasons:   reasons,
+		Notes:     d.Notes,
+		Changes:   d.Changes,
+		CreatedAt: d.CreatedAt,
+	}
+	if withReviewer && d.Reviewer.ID != uuid.Nil {
+		response.Reviewer = d.Reviewer.ToResponse()
+	}
+
+	return response
+}
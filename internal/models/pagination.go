@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PageQuery selects a page of a list ordered newest first, either by offset
+// or, when After is set, by keyset after the last row of the previous page
+type PageQuery struct {
+	Offset int
+	Limit  int
+	After  *Cursor
+}
+
+// Cursor is the position of a row in a list ordered by created_at and id
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(b, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &cursor, nil
+}
+
+// NewPageQuery builds a page query from the page, limit and cursor request
+// parameters. A cursor takes precedence over the page number.
+func NewPageQuery(page, limit int, cursor string) (PageQuery, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := PageQuery{Offset: (page - 1) * limit, Limit: limit}
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil {
+			return PageQuery{}, err
+		}
+		query.Offset = 0
+		query.After = after
+	}
+
+	return query, nil
+}
+
+// TrimPage drops the extra row fetched to detect a following page and returns
+// the cursor of that page, or an empty string on the last page
+func TrimPage[T any](items []T, page PageQuery, cursorOf func(T) Cursor) ([]T, string) {
+	if len(items) <= page.Limit {
+		return items, ""
+	}
+
+	items = items[:page.Limit]
+	return items, cursorOf(items[len(items)-1]).Encode()
+}
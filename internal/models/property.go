@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,8 +27,21 @@ const (
 	PropertyStatusActive   PropertyStatus = "active"
 	PropertyStatusInactive PropertyStatus = "inactive"
 	PropertyStatusPending  PropertyStatus = "pending"
+	// saved by the host but not yet submitted for approval
+	PropertyStatusDraft PropertyStatus = "draft"
+	// turned down by a moderator, the host can fix it and submit it again
+	PropertyStatusRejected PropertyStatus = "rejected"
 )
 
+// IsValidPropertyType reports whether t is a supported property type
+func IsValidPropertyType(t PropertyType) bool {
+	switch t {
+	case PropertyTypeApartment, PropertyTypeHouse, PropertyTypeCondo, PropertyTypeVilla, PropertyTypeCabin, PropertyTypeStudio:
+		return true
+	}
+	return false
+}
+
 type Property struct {
 	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	HostID        uuid.UUID      `json:"host_id" gorm:"type:uuid;not null"`
@@ -39,6 +54,8 @@ type Property struct {
 	MaxGuests     int            `json:"max_guests" gorm:"not null" validate:"required,min=1,max=20"`
 	Bedrooms      int            `json:"bedrooms" gorm:"not null" validate:"required,min=0,max=20"`
 	Bathrooms     int            `json:"bathrooms" gorm:"not null" validate:"required,min=1,max=20"`
+	Rooms         PropertyRooms  `json:"rooms" gorm:"type:jsonb;not null;default:'[]'"`
+	TotalBeds     int            `json:"total_beds" gorm:"not null;default:0"`
 	Address       string         `json:"address" gorm:"not null" validate:"required"`
 	City          string         `json:"city" gorm:"not null" validate:"required"`
 	State         string         `json:"state" gorm:"not null" validate:"required"`
@@ -47,39 +64,60 @@ type Property struct {
 	Latitude      float64        `json:"latitude" gorm:"type:decimal(10,8)"`
 	Longitude     float64        `json:"longitude" gorm:"type:decimal(11,8)"`
 	Amenities     pq.StringArray `gorm:"type:text[]" json:"amenities"`
-	Images        pq.StringArray `gorm:"type:text[]" json:"images"`
-	Rules         pq.StringArray `gorm:"type:text[]" json:"rules"`
-	CheckInTime   time.Time      `json:"check_in_time" gorm:"type:time"`
-	CheckOutTime  time.Time      `json:"check_out_time" gorm:"type:time"`
+	// amenities from before the catalog that match no entry yet, kept for
+	// an admin to add to the catalog
+	UnmappedAmenities pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"-"`
+	Images            pq.StringArray `gorm:"type:text[]" json:"images"`
+	Rules             pq.StringArray `gorm:"type:text[]" json:"rules"`
+	HouseRules        HouseRules     `json:"house_rules" gorm:"embedded"`
+	// AccessibilityFeature values, with anything else in AccessibilityNotes
+	AccessibilityFeatures pq.StringArray `gorm:"type:text[]" json:"accessibility_features"`
+	AccessibilityNotes    string         `json:"accessibility_notes" gorm:"type:text;not null;default:''"`
+	CheckInTime           time.Time      `json:"check_in_time" gorm:"type:time"`
+	CheckOutTime          time.Time      `json:"check_out_time" gorm:"type:time"`
+	// denormalized from reviews, kept current by ReviewService
+	AverageRating float64        `json:"average_rating" gorm:"->;type:decimal(3,2);not null;default:0"`
+	ReviewCount   int            `json:"review_count" gorm:"->;not null;default:0"`
+	SubmittedAt   *time.Time     `json:"submitted_at,omitempty"`
+	ApprovedAt    *time.Time     `json:"approved_at,omitempty"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 	Host          User           `json:"host,omitempty" gorm:"foreignKey:HostID"`
 	Bookings      []Booking      `json:"bookings,omitempty" gorm:"foreignKey:PropertyID"`
 	Reviews       []Review       `json:"reviews,omitempty" gorm:"foreignKey:PropertyID"`
+	// only populated by searches around a point
+	DistanceKm *float64 `json:"-" gorm:"->;-:migration"`
+	// material changes waiting for a moderator, see PropertyChanges
+	PendingChanges *PropertyChanges `json:"-" gorm:"type:jsonb"`
 }
 
 type PropertyCreateRequest struct {
-	Title         string       `json:"title" validate:"required,min=10,max=100"`
-	Description   string       `json:"description" validate:"required,min=50"`
-	Type          PropertyType `json:"type" validate:"required,oneof=apartment house condo villa cabin studio"`
-	PricePerNight float64      `json:"price_per_night" validate:"required,min=1"`
-	Currency      string       `json:"currency"`
-	MaxGuests     int          `json:"max_guests" validate:"required,min=1,max=20"`
-	Bedrooms      int          `json:"bedrooms" validate:"required,min=0,max=20"`
-	Bathrooms     int          `json:"bathrooms" validate:"required,min=1,max=20"`
-	Address       string       `json:"address" validate:"required"`
-	City          string       `json:"city" validate:"required"`
-	State         string       `json:"state" validate:"required"`
-	Country       string       `json:"country" validate:"required"`
-	ZipCode       string       `json:"zip_code" validate:"required"`
-	Latitude      float64      `json:"latitude"`
-	Longitude     float64      `json:"longitude"`
-	Amenities     []string     `json:"amenities"`
-	Images        []string     `json:"images"`
-	Rules         []string     `json:"rules"`
-	CheckInTime   time.Time    `json:"check_in_time"`
-	CheckOutTime  time.Time    `json:"check_out_time"`
+	Title         string         `json:"title" validate:"required,min=10,max=100"`
+	Description   string         `json:"description" validate:"required,min=50"`
+	Type          PropertyType   `json:"type" validate:"required,oneof=apartment house condo villa cabin studio"`
+	PricePerNight float64        `json:"price_per_night" validate:"required,min=1"`
+	Currency      string         `json:"currency"`
+	MaxGuests     int            `json:"max_guests" validate:"required,min=1,max=20"`
+	Bedrooms      int            `json:"bedrooms" validate:"required,min=0,max=20"`
+	Bathrooms     int            `json:"bathrooms" validate:"required,min=1,max=20"`
+	Rooms         []PropertyRoom `json:"rooms" validate:"omitempty,max=30,dive"`
+	Address       string         `json:"address" validate:"required"`
+	City          string         `json:"city" validate:"required"`
+	State         string         `json:"state" validate:"required"`
+	Country       string         `json:"country" validate:"required"`
+	ZipCode       string         `json:"zip_code" validate:"required"`
+	Latitude      float64        `json:"latitude"`
+	Longitude     float64        `json:"longitude"`
+	Amenities     []string       `json:"amenities"`
+	Images        []string       `json:"images"`
+	Rules         []string       `json:"rules"`
+	HouseRules    HouseRules     `json:"house_rules"`
+	// AccessibilityFeature values
+	AccessibilityFeatures []string  `json:"accessibility_features"`
+	AccessibilityNotes    string    `json:"accessibility_notes"`
+	CheckInTime           time.Time `json:"check_in_time"`
+	CheckOutTime          time.Time `json:"check_out_time"`
 }
 
 type PropertyUpdateRequest struct {
@@ -92,6 +130,7 @@ type PropertyUpdateRequest struct {
 	MaxGuests     int            `json:"max_guests,omitempty" validate:"omitempty,min=1,max=20"`
 	Bedrooms      int            `json:"bedrooms,omitempty" validate:"omitempty,min=0,max=20"`
 	Bathrooms     int            `json:"bathrooms,omitempty" validate:"omitempty,min=1,max=20"`
+	Rooms         []PropertyRoom `json:"rooms,omitempty" validate:"omitempty,max=30,dive"`
 	Address       string         `json:"address,omitempty"`
 	City          string         `json:"city,omitempty"`
 	State         string         `json:"state,omitempty"`
@@ -102,23 +141,124 @@ type PropertyUpdateRequest struct {
 	Amenities     []string       `json:"amenities,omitempty"`
 	Images        []string       `json:"images,omitempty"`
 	Rules         []string       `json:"rules,omitempty"`
-	CheckInTime   time.Time      `json:"check_in_time"`
-	CheckOutTime  time.Time      `json:"check_out_time"`
+	// replaces all house rules when given
+	HouseRules            *HouseRules `json:"house_rules,omitempty"`
+	AccessibilityFeatures []string    `json:"accessibility_features,omitempty"`
+	AccessibilityNotes    *string     `json:"accessibility_notes,omitempty"`
+	CheckInTime           time.Time   `json:"check_in_time"`
+	CheckOutTime          time.Time   `json:"check_out_time"`
 }
 
 type PropertySearchRequest struct {
-	City      string    `json:"city" form:"city"`
-	State     string    `json:"state" form:"state"`
-	Country   string    `json:"country" form:"country"`
-	CheckIn   time.Time `json:"check_in" form:"check_in"`
-	CheckOut  time.Time `json:"check_out" form:"check_out"`
-	Guests    int       `json:"guests" form:"guests"`
-	MinPrice  float64   `json:"min_price" form:"min_price"`
-	MaxPrice  float64   `json:"max_price" form:"max_price"`
-	Type      string    `json:"type" form:"type"`
-	Amenities []string  `json:"amenities" form:"amenities"`
-	Page      int       `json:"page" form:"page"`
-	Limit     int       `json:"limit" form:"limit"`
+	Query       string    `json:"q" form:"q"`
+	City        string    `json:"city" form:"city"`
+	State       string    `json:"state" form:"state"`
+	Country     string    `json:"country" form:"country"`
+	CheckIn     time.Time `json:"check_in" form:"check_in"`
+	CheckOut    time.Time `json:"check_out" form:"check_out"`
+	Guests      int       `json:"guests" form:"guests"`
+	MinBedrooms int       `json:"min_bedrooms,omitempty" form:"min_bedrooms"`
+	MinBeds     int       `json:"min_beds,omitempty" form:"min_beds"`
+	MinPrice    float64   `json:"min_price" form:"min_price"`
+	MaxPrice    float64   `json:"max_price" form:"max_price"`
+	Type        string    `json:"type" form:"type"`
+	Amenities   []string  `json:"amenities" form:"amenities"`
+	// house rules a listing must state, nil for any
+	PetsAllowed     *bool `json:"pets_allowed,omitempty" form:"pets_allowed"`
+	SmokingAllowed  *bool `json:"smoking_allowed,omitempty" form:"smoking_allowed"`
+	EventsAllowed   *bool `json:"events_allowed,omitempty" form:"events_allowed"`
+	ChildrenAllowed *bool `json:"children_allowed,omitempty" form:"children_allowed"`
+	InfantsAllowed  *bool `json:"infants_allowed,omitempty" form:"infants_allowed"`
+	// listings must have every one of these AccessibilityFeature values
+	Accessibility []string     `json:"accessibility,omitempty" form:"accessibility"`
+	Lat           *float64     `json:"lat,omitempty" form:"lat"`
+	Lng           *float64     `json:"lng,omitempty" form:"lng"`
+	RadiusKm      float64      `json:"radius_km,omitempty" form:"radius_km"`
+	BBox          *BoundingBox `json:"bbox,omitempty" form:"-"`
+	Sort          string       `json:"sort,omitempty" form:"sort"`
+	Page          int          `json:"page" form:"page"`
+	Limit         int          `json:"limit" form:"limit"`
+	// only set by the saved search matcher to skip listings it already saw
+	ChangedSince *time.Time `json:"-" form:"-"`
+	// language q is written in, from the locale of the request. Matches
+	// listings translated to it as well as the original text. Kept with saved
+	// searches so their alerts match the same translations.
+	Locale string `json:"locale,omitempty" form:"-"`
+}
+
+// UnmarshalJSON takes check_in and check_out as dates, YYYY-MM-DD like the
+// search query string, as well as RFC 3339 times
+func (r *PropertySearchRequest) UnmarshalJSON(data []byte) error {
+	type plain PropertySearchRequest
+	aux := struct {
+		*plain
+		CheckIn  *string `json:"check_in"`
+		CheckOut *string `json:"check_out"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var err error
+	if r.CheckIn, err = parseSearchDate(aux.CheckIn); err != nil {
+		return errors.New("invalid check_in format. Use YYYY-MM-DD")
+	}
+	if r.CheckOut, err = parseSearchDate(aux.CheckOut); err != nil {
+		return errors.New("invalid check_out format. Use YYYY-MM-DD")
+	}
+	return nil
+}
+
+func parseSearchDate(value *string) (time.Time, error) {
+	if value == nil || *value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse("2006-01-02", *value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, *value)
+}
+
+// sort orders accepted by PropertySearchRequest.Sort
+const (
+	SearchSortPriceAsc  = "price_asc"
+	SearchSortPriceDesc = "price_desc"
+	SearchSortRating    = "rating"
+	SearchSortNewest    = "newest"
+	SearchSortDistance  = "distance"
+	SearchSortRelevance = "relevance"
+)
+
+// IsValidSearchSort reports whether sort is a supported search order
+func IsValidSearchSort(sort string) bool {
+	switch sort {
+	case SearchSortPriceAsc, SearchSortPriceDesc, SearchSortRating, SearchSortNewest, SearchSortDistance, SearchSortRelevance:
+		return true
+	}
+	return false
+}
+
+// BoundingBox is a map viewport. MinLng is greater than MaxLng when the box
+// crosses the antimeridian.
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// MaxSearchRadiusKm is the largest radius a search may cover
+const MaxSearchRadiusKm = 500
+
+// Validate checks that the box lies on the globe with its latitudes in order
+func (b *BoundingBox) Validate() error {
+	if b.MinLat < -90 || b.MaxLat > 90 || b.MinLat > b.MaxLat {
+		return errors.New("invalid bbox latitude range")
+	}
+	if b.MinLng < -180 || b.MinLng > 180 || b.MaxLng < -180 || b.MaxLng > 180 {
+		return errors.New("invalid bbox longitude range")
+	}
+	return nil
 }
 
 type PropertyResponse struct {
@@ -133,6 +273,8 @@ type PropertyResponse struct {
 	MaxGuests     int            `json:"max_guests"`
 	Bedrooms      int            `json:"bedrooms"`
 	Bathrooms     int            `json:"bathrooms"`
+	Rooms         PropertyRooms  `json:"rooms"`
+	TotalBeds     int            `json:"total_beds"`
 	Address       string         `json:"address"`
 	City          string         `json:"city"`
 	State         string         `json:"state"`
@@ -143,11 +285,28 @@ type PropertyResponse struct {
 	Amenities     []string       `json:"amenities"`
 	Images        []string       `json:"images"`
 	Rules         []string       `json:"rules"`
-	CheckInTime   time.Time      `json:"check_in_time"`
-	CheckOutTime  time.Time      `json:"check_out_time"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	Host          *UserResponse  `json:"host,omitempty"`
+	HouseRules    HouseRules     `json:"house_rules"`
+	// AccessibilityFeature values
+	AccessibilityFeatures []string   `json:"accessibility_features"`
+	AccessibilityNotes    string     `json:"accessibility_notes"`
+	CheckInTime           time.Time  `json:"check_in_time"`
+	CheckOutTime          time.Time  `json:"check_out_time"`
+	AverageRating         float64    `json:"average_rating"`
+	ReviewCount           int        `json:"review_count"`
+	SubmittedAt           *time.Time `json:"submitted_at,omitempty"`
+	ApprovedAt            *time.Time `json:"approved_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+	DistanceKm            *float64   `json:"distance_km,omitempty"`
+	// only set on similar-listing recommendations, between 0 and 1
+	SimilarityScore *float64 `json:"similarity_score,omitempty"`
+	// only set when the request is authenticated
+	IsFavorited *bool         `json:"is_favorited,omitempty"`
+	Host        *UserResponse `json:"host,omitempty"`
+	// only shown to the host and moderators
+	PendingChanges *PropertyChanges `json:"pending_changes,omitempty"`
+	// set when the title, description and rules are shown in a translation
+	Locale string `json:"locale,omitempty"`
 }
 
 // TableName returns the table name for the Property model
@@ -155,34 +314,49 @@ func (Property) TableName() string {
 	return "properties"
 }
 
+// PageCursor returns the position of the property in paginated lists
+func (p *Property) PageCursor() Cursor {
+	return Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
 // converts Property to PropertyResponse
 func (p *Property) ToResponse() *PropertyResponse {
 	response := &PropertyResponse{
-		ID:            p.ID,
-		HostID:        p.HostID,
-		Title:         p.Title,
-		Description:   p.Description,
-		Type:          p.Type,
-		Status:        p.Status,
-		PricePerNight: p.PricePerNight,
-		Currency:      p.Currency,
-		MaxGuests:     p.MaxGuests,
-		Bedrooms:      p.Bedrooms,
-		Bathrooms:     p.Bathrooms,
-		Address:       p.Address,
-		City:          p.City,
-		State:         p.State,
-		Country:       p.Country,
-		ZipCode:       p.ZipCode,
-		Latitude:      p.Latitude,
-		Longitude:     p.Longitude,
-		Amenities:     p.Amenities,
-		Images:        p.Images,
-		Rules:         p.Rules,
-		CheckInTime:   p.CheckInTime,
-		CheckOutTime:  p.CheckOutTime,
-		CreatedAt:     p.CreatedAt,
-		UpdatedAt:     p.UpdatedAt,
+		ID:                    p.ID,
+		HostID:                p.HostID,
+		Title:                 p.Title,
+		Description:           p.Description,
+		Type:                  p.Type,
+		Status:                p.Status,
+		PricePerNight:         p.PricePerNight,
+		Currency:              p.Currency,
+		MaxGuests:             p.MaxGuests,
+		Bedrooms:              p.Bedrooms,
+		Bathrooms:             p.Bathrooms,
+		Rooms:                 p.Rooms,
+		TotalBeds:             p.TotalBeds,
+		Address:               p.Address,
+		City:                  p.City,
+		State:                 p.State,
+		Country:               p.Country,
+		ZipCode:               p.ZipCode,
+		Latitude:              p.Latitude,
+		Longitude:             p.Longitude,
+		Amenities:             p.Amenities,
+		Images:                p.Images,
+		Rules:                 p.Rules,
+		HouseRules:            p.HouseRules,
+		AccessibilityFeatures: p.AccessibilityFeatures,
+		AccessibilityNotes:    p.AccessibilityNotes,
+		CheckInTime:           p.CheckInTime,
+		CheckOutTime:          p.CheckOutTime,
+		AverageRating:         p.AverageRating,
+		ReviewCount:           p.ReviewCount,
+		SubmittedAt:           p.SubmittedAt,
+		ApprovedAt:            p.ApprovedAt,
+		CreatedAt:             p.CreatedAt,
+		UpdatedAt:             p.UpdatedAt,
+		DistanceKm:            p.DistanceKm,
 	}
 
 	if p.Host.ID != uuid.Nil {
@@ -192,10 +366,62 @@ func (p *Property) ToResponse() *PropertyResponse {
 	return response
 }
 
+// ToHostResponse converts the property for its host, including the changes
+// that wait for a moderator
+func (p *Property) ToHostResponse() *PropertyResponse {
+	response := p.ToResponse()
+	response.PendingChanges = p.PendingChanges
+	return response
+}
+
 type PropertySearchResponse struct {
 	Properties []*PropertyResponse `json:"properties"`
 	Total      int64               `json:"total"`
 	Page       int                 `json:"page"`
 	Limit      int                 `json:"limit"`
 	TotalPages int                 `json:"total_pages"`
+	Facets     *SearchFacets       `json:"facets,omitempty"`
+}
+
+// a city, state and country combination offered while a guest types a destination
+type DestinationSuggestion struct {
+	City         string `json:"city"`
+	State        string `json:"state"`
+	Country      string `json:"country"`
+	Label        string `json:"label" gorm:"-"`
+	ListingCount int64  `json:"listing_count"`
+}
+
+// upper bounds of the price buckets counted in search facets, the last bucket is open ended
+var SearchPriceBucketBounds = []float64{50, 100, 200, 300, 500}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type PriceBucketCount struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int64    `json:"count"`
+}
+
+type SearchFacets struct {
+	Types        []FacetCount       `json:"types"`
+	Amenities    []FacetCount       `json:"amenities"`
+	Bedrooms     []FacetCount       `json:"bedrooms"`
+	PriceBuckets []PriceBucketCount `json:"price_buckets"`
+}
+
+// NewPriceBuckets returns empty buckets for SearchPriceBucketBounds
+func NewPriceBuckets() []PriceBucketCount {
+	buckets := make([]PriceBucketCount, len(SearchPriceBucketBounds)+1)
+	min := 0.0
+	for i := range SearchPriceBucketBounds {
+		max := SearchPriceBucketBounds[i]
+		buckets[i] = PriceBucketCount{Min: min, Max: &max}
+		min = max
+	}
+	buckets[len(buckets)-1] = PriceBucketCount{Min: min}
+	return buckets
 }
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The draft requests each cover one step of listing a property. Fields left
+// out are not changed, so a host can fill in a step over several requests.
+
+type DraftBasicsRequest struct {
+	Title       *string       `json:"title,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Type        *PropertyType `json:"type,omitempty"`
+}
+
+type DraftLocationRequest struct {
+	Address   *string  `json:"address,omitempty"`
+	City      *string  `json:"city,omitempty"`
+	State     *string  `json:"state,omitempty"`
+	Country   *string  `json:"country,omitempty"`
+	ZipCode   *string  `json:"zip_code,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+type DraftCapacityRequest struct {
+	MaxGuests *int           `json:"max_guests,omitempty"`
+	Bedrooms  *int           `json:"bedrooms,omitempty"`
+	Bathrooms *int           `json:"bathrooms,omitempty"`
+	Rooms     []PropertyRoom `json:"rooms,omitempty"`
+}
+
+type DraftAmenitiesRequest struct {
+	Amenities []string `json:"amenities"`
+}
+
+type DraftPricingRequest struct {
+	PricePerNight *float64 `json:"price_per_night,omitempty"`
+	Currency      *string  `json:"currency,omitempty"`
+}
+
+type DraftRulesRequest struct {
+	Rules                 []string    `json:"rules,omitempty"`
+	HouseRules            *HouseRules `json:"house_rules,omitempty"`
+	AccessibilityFeatures []string    `json:"accessibility_features,omitempty"`
+	AccessibilityNotes    *string     `json:"accessibility_notes,omitempty"`
+	CheckInTime           *time.Time  `json:"check_in_time,omitempty"`
+	CheckOutTime          *time.Time  `json:"check_out_time,omitempty"`
+}
+
+// ListingCompleteness tells a host how far a listing is from being ready to
+// submit. Score is the percentage of required fields that are filled in.
+type ListingCompleteness struct {
+	Score         int      `json:"score"`
+	MissingFields []string `json:"missing_fields"`
+}
+
+type PropertyDraftResponse struct {
+	Property     *PropertyResponse    `json:"property"`
+	Completeness *ListingCompleteness `json:"completeness"`
+}
+
+// the fields a listing needs before it can be submitted, checked the same way
+// as PropertyCreateRequest
+var listingRequirements = []struct {
+	field string
+	met   func(p *Property) bool
+}{
+	{"title", func(p *Property) bool {
+		n := utf8.RuneCountInString(strings.TrimSpace(p.Title))
+		return n >= 10 && n <= 100
+	}},
+	{"description", func(p *Property) bool { return utf8.RuneCountInString(strings.TrimSpace(p.Description)) >= 50 }},
+	{"type", func(p *Property) bool { return IsValidPropertyType(p.Type) }},
+	{"address", func(p *Property) bool { return strings.TrimSpace(p.Address) != "" }},
+	{"city", func(p *Property) bool { return strings.TrimSpace(p.City) != "" }},
+	{"state", func(p *Property) bool { return strings.TrimSpace(p.State) != "" }},
+	{"country", func(p *Property) bool { return strings.TrimSpace(p.Country) != "" }},
+	{"zip_code", func(p *Property) bool { return strings.TrimSpace(p.ZipCode) != "" }},
+	{"max_guests", func(p *Property) bool { return p.MaxGuests >= 1 && p.MaxGuests <= 20 }},
+	{"bathrooms", func(p *Property) bool { return p.Bathrooms >= 1 && p.Bathrooms <= 20 }},
+	{"price_per_night", func(p *Property) bool { return p.PricePerNight >= 1 }},
+}
+
+// Completeness checks the property against the fields a listing requires
+func (p *Property) Completeness() *ListingCompleteness {
+	missing := []string{}
+	for _, requirement := range listingRequirements {
+		if !requirement.met(p) {
+			missing = append(missing, requirement.field)
+		}
+	}
+
+	return &ListingCompleteness{
+		Score:         100 * (len(listingRequirements) - len(missing)) / len(listingRequirements),
+		MissingFields: missing,
+	}
+}
+
+func (p *Property) ToDraftResponse() *PropertyDraftResponse {
+	return &PropertyDraftResponse{
+		Property:     p.ToHostResponse(),
+		Completeness: p.Completeness(),
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImageVariant is a resized copy of an uploaded image
+type ImageVariant struct {
+	Name   string `json:"name"`
+	Key    string `json:"key"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageVariants are stored as jsonb on a property image
+type ImageVariants []ImageVariant
+
+func (v ImageVariants) Value() (driver.Value, error) {
+	return jsonValue(v)
+}
+
+func (v *ImageVariants) Scan(src interface{}) error {
+	return scanJSON(src, v)
+}
+
+// PropertyImage is a photo of a property. Uploaded images have a Key in the
+// object store; images added as plain URLs before uploads existed have none.
+// Property.Images mirrors the URLs, cover first, then by Position.
+// Photos added to an approved listing are PendingReview and stay hidden until
+// a moderator approves them.
+type PropertyImage struct {
+	ID            uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PropertyID    uuid.UUID     `json:"property_id" gorm:"type:uuid;not null;index"`
+	Key           string        `json:"-" gorm:"not null;default:''"`
+	URL           string        `json:"url" gorm:"not null"`
+	ContentType   string        `json:"content_type"`
+	Size          int64         `json:"size"`
+	Width         int           `json:"width"`
+	Height        int           `json:"height"`
+	Variants      ImageVariants `json:"-" gorm:"type:jsonb;not null;default:'[]'"`
+	Caption       string        `json:"caption" gorm:"type:varchar(500);not null;default:''"`
+	Position      int           `json:"position" gorm:"not null;default:0"`
+	IsCover       bool          `json:"is_cover" gorm:"not null;default:false"`
+	PendingReview bool          `json:"pending_review" gorm:"not null;default:false"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type PropertyImageUpdateRequest struct {
+	Caption *string `json:"caption,omitempty" validate:"omitempty,max=500"`
+	// only true is accepted; to move the cover, set it on another image
+	IsCover *bool `json:"is_cover,omitempty"`
+}
+
+type PropertyImageReorderRequest struct {
+	ImageIDs []uuid.UUID `json:"image_ids" validate:"required"`
+}
+
+type ImageVariantResponse struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type PropertyImageResponse struct {
+	ID            uuid.UUID               `json:"id"`
+	URL           string                  `json:"url"`
+	Width         int                     `json:"width,omitempty"`
+	Height        int                     `json:"height,omitempty"`
+	Variants      []*ImageVariantResponse `json:"variants"`
+	Caption       string                  `json:"caption"`
+	Position      int                     `json:"position"`
+	IsCover       bool                    `json:"is_cover"`
+	PendingReview bool                    `json:"pending_review,omitempty"`
+	CreatedAt     time.Time               `json:"created_at"`
+}
+
+func (PropertyImage) TableName() string {
+	return "property_images"
+}
+
+func (i *PropertyImage) ToResponse() *PropertyImageResponse {
+	variants := make([]*ImageVariantResponse, len(i.Variants))
+	for j, variant := range i.Variants {
+		variants[j] = &ImageVariantResponse{
+			Name:   variant.Name,
+			URL:    variant.URL,
+			Width:  variant.Width,
+			Height: variant.Height,
+		}
+	}
+
+	return &PropertyImageResponse{
+		ID:            i.ID,
+		URL:           i.URL,
+		Width:         i.Width,
+		Height:        i.Height,
+		Variants:      variants,
+		Caption:       i.Caption,
+		Position:      i.Position,
+		IsCover:       i.IsCover,
+		PendingReview: i.PendingReview,
+		CreatedAt:     i.CreatedAt,
+	}
+}
+
+// ObjectKeys returns the keys of the image and its variants in the object
+// store, none for images that were never uploaded
+func (i *PropertyImage) ObjectKeys() []string {
+	var keys []string
+	if i.Key != "" {
+		keys = append(keys, i.Key)
+	}
+	for _, variant := range i.Variants {
+		if variant.Key != "" {
+			keys = append(keys, variant.Key)
+		}
+	}
+	return keys
+}
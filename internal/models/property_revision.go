@@ -0,0 +1,244 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type PropertyRevisionAction string
+
+const (
+	PropertyRevisionCreated    PropertyRevisionAction = "created"
+	PropertyRevisionUpdated    PropertyRevisionAction = "updated"
+	PropertyRevisionSubmitted  PropertyRevisionAction = "submitted"
+	PropertyRevisionApproved   PropertyRevisionAction = "approved"
+	PropertyRevisionRejected   PropertyRevisionAction = "rejected"
+	PropertyRevisionRolledBack PropertyRevisionAction = "rolled_back"
+)
+
+// time of day format of the check-in and check-out times in a snapshot
+const snapshotTimeFormat = "15:04:05"
+
+// PropertySnapshot is what a listing showed guests at one point in time.
+// Changes waiting for a moderator are not part of it.
+type PropertySnapshot struct {
+	Title         string         `json:"title"`
+	Description   string         `json:"description"`
+	Type          PropertyType   `json:"type"`
+	Status        PropertyStatus `json:"status"`
+	PricePerNight float64        `json:"price_per_night"`
+	Currency      string         `json:"currency"`
+	MaxGuests     int            `json:"max_guests"`
+	Bedrooms      int            `json:"bedrooms"`
+	Bathrooms     int            `json:"bathrooms"`
+	Rooms         PropertyRooms  `json:"rooms"`
+	Address       string         `json:"address"`
+	City          string         `json:"city"`
+	State         string         `json:"state"`
+	Country       string         `json:"country"`
+	ZipCode       string         `json:"zip_code"`
+	Latitude      float64        `json:"latitude"`
+	Longitude     float64        `json:"longitude"`
+	Amenities     []string       `json:"amenities"`
+	Images        []string       `json:"images"`
+	Rules         []string       `json:"rules"`
+	HouseRules    HouseRules     `json:"house_rules"`
+	// AccessibilityFeature values
+	AccessibilityFeatures []string `json:"accessibility_features"`
+	AccessibilityNotes    string   `json:"accessibility_notes"`
+	CheckInTime           string   `json:"check_in_time"`
+	CheckOutTime          string   `json:"check_out_time"`
+}
+
+func (s PropertySnapshot) Value() (driver.Value, error) {
+	return jsonValue(s)
+}
+
+func (s *PropertySnapshot) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// PropertyRevision is a snapshot of a listing taken whenever it changed.
+// Revisions are numbered per property and never changed; bookings point at
+// the revision that was live when they were made.
+type PropertyRevision struct {
+	ID            uuid.UUID              `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PropertyID    uuid.UUID              `json:"property_id" gorm:"type:uuid;not null;uniqueIndex:idx_property_revisions_number"`
+	Number        int                    `json:"number" gorm:"not null;uniqueIndex:idx_property_revisions_number"`
+	Action        PropertyRevisionAction `json:"action" gorm:"type:varchar(20);not null"`
+	ChangedFields pq.StringArray         `json:"changed_fields" gorm:"type:text[]"`
+	ActorID       uuid.UUID              `json:"actor_id" gorm:"type:uuid;not null"`
+	Snapshot      PropertySnapshot       `json:"snapshot" gorm:"type:jsonb;not null"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+type PropertyRevisionResponse struct {
+	ID            uuid.UUID              `json:"id"`
+	PropertyID    uuid.UUID              `json:"property_id"`
+	Number        int                    `json:"number"`
+	Action        PropertyRevisionAction `json:"action"`
+	ChangedFields []string               `json:"changed_fields"`
+	ActorID       uuid.UUID              `json:"actor_id"`
+	Snapshot      PropertySnapshot       `json:"snapshot"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+func (PropertyRevision) TableName() string {
+	return "property_revisions"
+}
+
+// PageCursor returns the position of the revision in paginated lists
+func (r *PropertyRevision) PageCursor() Cursor {
+	return Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+}
+
+func (r *PropertyRevision) ToResponse() *PropertyRevisionResponse {
+	changed := []string(r.ChangedFields)
+	if changed == nil {
+		changed = []string{}
+	}
+
+	return &PropertyRevisionResponse{
+		ID:            r.ID,
+		PropertyID:    r.PropertyID,
+		Number:        r.Number,
+		Action:        r.Action,
+		ChangedFields: changed,
+		ActorID:       r.ActorID,
+		Snapshot:      r.Snapshot,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+// Snapshot returns what the listing currently shows guests
+func (p *Property) Snapshot() PropertySnapshot {
+	rooms := p.Rooms
+	if rooms == nil {
+		rooms = PropertyRooms{}
+	}
+
+	return PropertySnapshot{
+		Title:                 p.Title,
+		Description:           p.Description,
+		Type:                  p.Type,
+		Status:                p.Status,
+		PricePerNight:         p.PricePerNight,
+		Currency:              p.Currency,
+		MaxGuests:             p.MaxGuests,
+		Bedrooms:              p.Bedrooms,
+		Bathrooms:             p.Bathrooms,
+		Rooms:                 rooms,
+		Address:               p.Address,
+		City:                  p.City,
+		State:                 p.State,
+		Country:               p.Country,
+		ZipCode:               p.ZipCode,
+		Latitude:              p.Latitude,
+		Longitude:             p.Longitude,
+		Amenities:             nonNilStrings(p.Amenities),
+		Images:                nonNilStrings(p.Images),
+		Rules:                 nonNilStrings(p.Rules),
+		HouseRules:            p.HouseRules,
+		AccessibilityFeatures: nonNilStrings(p.AccessibilityFeatures),
+		AccessibilityNotes:    p.AccessibilityNotes,
+		CheckInTime:           p.CheckInTime.Format(snapshotTimeFormat),
+		CheckOutTime:          p.CheckOutTime.Format(snapshotTimeFormat),
+	}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// ChangedFields returns the JSON names of the fields that differ from
+// previous, in the order they appear in the snapshot
+func (s PropertySnapshot) ChangedFields(previous PropertySnapshot) []string {
+	current, err := snapshotFields(s)
+	if err != nil {
+		return nil
+	}
+	before, err := snapshotFields(previous)
+	if err != nil {
+		return nil
+	}
+
+	changed := []string{}
+	for _, field := range snapshotFieldOrder {
+		if !bytes.Equal(current[field], before[field]) {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+// field names of PropertySnapshot in declaration order
+var snapshotFieldOrder = []string{
+	"title", "description", "type", "status", "price_per_night", "currency",
+	"max_guests", "bedrooms", "bathrooms", "rooms", "address", "city", "state",
+	"country", "zip_code", "latitude", "longitude", "amenities", "images",
+	"rules", "house_rules", "accessibility_features", "accessibility_notes",
+	"check_in_time", "check_out_time",
+}
+
+func snapshotFields(s PropertySnapshot) (map[string]json.RawMessage, error) {
+	// revisions taken before listings had accessibility features
+	s.AccessibilityFeatures = nonNilStrings(s.AccessibilityFeatures)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// RestoreTo sets the fields of the property a host can change without a
+// moderator to the snapshot. Material fields are left to Changes and the
+// status to moderation.
+func (s *PropertySnapshot) RestoreTo(p *Property) {
+	p.Title = s.Title
+	p.Description = s.Description
+	p.PricePerNight = s.PricePerNight
+	p.Currency = s.Currency
+	p.MaxGuests = s.MaxGuests
+	p.Bedrooms = s.Bedrooms
+	p.Bathrooms = s.Bathrooms
+	p.Rooms = s.Rooms
+	p.TotalBeds = s.Rooms.TotalBeds()
+	p.Amenities = s.Amenities
+	p.Rules = s.Rules
+	p.HouseRules = s.HouseRules
+	p.AccessibilityFeatures = s.AccessibilityFeatures
+	p.AccessibilityNotes = s.AccessibilityNotes
+	if t, err := time.Parse(snapshotTimeFormat, s.CheckInTime); err == nil {
+		p.CheckInTime = t
+	}
+	if t, err := time.Parse(snapshotTimeFormat, s.CheckOutTime); err == nil {
+		p.CheckOutTime = t
+	}
+}
+
+// Changes returns the material fields of the snapshot, including its images
+func (s *PropertySnapshot) Changes() *PropertyChanges {
+	return &PropertyChanges{
+		Type:      &s.Type,
+		Address:   &s.Address,
+		City:      &s.City,
+		State:     &s.State,
+		Country:   &s.Country,
+		ZipCode:   &s.ZipCode,
+		Latitude:  &s.Latitude,
+		Longitude: &s.Longitude,
+		Images:    s.Images,
+	}
+}
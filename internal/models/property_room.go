@@ -0,0 +1,90 @@
+package models
+
+import (
+	"database/sql/driver"
+)
+
+type RoomType string
+
+const (
+	RoomTypeBedroom     RoomType = "bedroom"
+	RoomTypeLivingRoom  RoomType = "living_room"
+	RoomTypeCommonSpace RoomType = "common_space"
+	RoomTypeOther       RoomType = "other"
+)
+
+type BedType string
+
+const (
+	BedTypeBed           BedType = "bed"
+	BedTypeSofaBed       BedType = "sofa_bed"
+	BedTypeBunkBed       BedType = "bunk_bed"
+	BedTypeFloorMattress BedType = "floor_mattress"
+	BedTypeCrib          BedType = "crib"
+)
+
+type BedSize string
+
+const (
+	BedSizeSingle BedSize = "single"
+	BedSizeDouble BedSize = "double"
+	BedSizeQueen  BedSize = "queen"
+	BedSizeKing   BedSize = "king"
+)
+
+// Bed is a number of beds of the same type and size in a room. Size is
+// required for regular beds and sofa beds and optional otherwise.
+type Bed struct {
+	Type  BedType `json:"type" validate:"required,oneof=bed sofa_bed bunk_bed floor_mattress crib"`
+	Size  BedSize `json:"size,omitempty" validate:"omitempty,oneof=single double queen king"`
+	Count int     `json:"count" validate:"required,min=1,max=10"`
+}
+
+// PropertyRoom describes a room of a listing and the beds in it
+type PropertyRoom struct {
+	Type RoomType `json:"type" validate:"required,oneof=bedroom living_room common_space other"`
+	// optional label shown to guests, e.g. "Loft"
+	Name    string `json:"name,omitempty" validate:"omitempty,max=50"`
+	Beds    []Bed  `json:"beds"`
+	Ensuite bool   `json:"ensuite"`
+}
+
+// PropertyRooms are stored as jsonb on a property
+type PropertyRooms []PropertyRoom
+
+func (r PropertyRooms) Value() (driver.Value, error) {
+	if r == nil {
+		r = PropertyRooms{}
+	}
+	return jsonValue(r)
+}
+
+func (r *PropertyRooms) Scan(src interface{}) error {
+	return scanJSON(src, r)
+}
+
+// Bedrooms returns the number of rooms that are bedrooms
+func (r PropertyRooms) Bedrooms() int {
+	count := 0
+	for _, room := range r {
+		if room.Type == RoomTypeBedroom {
+			count++
+		}
+	}
+	return count
+}
+
+// TotalBeds returns the number of beds across all rooms, kept on
+// Property.TotalBeds for searches. Cribs only fit infants, so they are not
+// counted.
+func (r PropertyRooms) TotalBeds() int {
+	total := 0
+	for _, room := range r {
+		for _, bed := range room.Beds {
+			if bed.Type != BedTypeCrib {
+				total += bed.Count
+			}
+		}
+	}
+	return total
+}
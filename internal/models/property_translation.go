@@ -0,0 +1,118 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/text/language"
+)
+
+// a language with an optional region, e.g. "es" or "pt-BR"
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2}|-[0-9]{3})?$`)
+
+// NormalizeLocale turns a locale such as "pt_br" into "pt-BR" and reports
+// whether it is well formed
+func NormalizeLocale(locale string) (string, bool) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	language, region, found := strings.Cut(locale, "-")
+	locale = strings.ToLower(language)
+	if found {
+		locale += "-" + strings.ToUpper(region)
+	}
+	return locale, localePattern.MatchString(locale)
+}
+
+// LocaleFallbacks returns the locales a translation is looked up in for
+// locale: the locale itself, then its base language
+func LocaleFallbacks(locale string) []string {
+	if base, _, found := strings.Cut(locale, "-"); found {
+		return []string{locale, base}
+	}
+	return []string{locale}
+}
+
+// MatchLocale returns the locale of available that best suits the preferred
+// languages, most wanted first, or DefaultLocale when none of them does
+func MatchLocale(preferred []language.Tag, available []string) string {
+	locales := []string{DefaultLocale}
+	supported := []language.Tag{language.Make(DefaultLocale)}
+	for _, locale := range available {
+		tag, err := language.Parse(locale)
+		if err != nil || locale == DefaultLocale {
+			continue
+		}
+		locales = append(locales, locale)
+		supported = append(supported, tag)
+	}
+
+	// unmatched languages get the first supported one, the default locale
+	_, index, _ := language.NewMatcher(supported).Match(preferred...)
+	return locales[index]
+}
+
+// PropertyTranslation is the title, description and rules of a listing in
+// another language. The original text is taken to be in DefaultLocale and is
+// shown for anything left empty.
+type PropertyTranslation struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PropertyID  uuid.UUID      `json:"property_id" gorm:"type:uuid;not null;uniqueIndex:idx_property_translations_locale"`
+	Locale      string         `json:"locale" gorm:"type:varchar(10);not null;uniqueIndex:idx_property_translations_locale"`
+	Title       string         `json:"title" gorm:"not null;default:''"`
+	Description string         `json:"description" gorm:"type:text;not null;default:''"`
+	Rules       pq.StringArray `json:"rules" gorm:"type:text[]"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+type PropertyTranslationRequest struct {
+	Title       string   `json:"title" validate:"omitempty,max=100"`
+	Description string   `json:"description"`
+	Rules       []string `json:"rules"`
+}
+
+type PropertyTranslationResponse struct {
+	Locale      string    `json:"locale"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Rules       []string  `json:"rules"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (PropertyTranslation) TableName() string {
+	return "property_translations"
+}
+
+func (t *PropertyTranslation) ToResponse() *PropertyTranslationResponse {
+	rules := []string(t.Rules)
+	if rules == nil {
+		rules = []string{}
+	}
+
+	return &PropertyTranslationResponse{
+		Locale:      t.Locale,
+		Title:       t.Title,
+		Description: t.Description,
+		Rules:       rules,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// Translate returns a copy of the response with the translated text, keeping
+// the original where the translation is empty
+func (r *PropertyResponse) Translate(t *PropertyTranslation) *PropertyResponse {
+	translated := *r
+	if t.Title != "" {
+		translated.Title = t.Title
+	}
+	if t.Description != "" {
+		translated.Description = t.Description
+	}
+	if len(t.Rules) > 0 {
+		translated.Rules = t.Rules
+	}
+	translated.Locale = t.Locale
+	return &translated
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusUnpaid PaymentStatus = "unpaid"
+	PaymentStatusPaid   PaymentStatus = "paid"
+	PaymentStatusVoid   PaymentStatus = "void"
+)
+
+// PaymentStatusFor derives the payment status shown on a receipt from the booking status
+func PaymentStatusFor(status BookingStatus) PaymentStatus {
+	switch status {
+	case BookingStatusConfirmed, BookingStatusCompleted:
+		return PaymentStatusPaid
+	case BookingStatusCancelled:
+		return PaymentStatusVoid
+	default:
+		return PaymentStatusUnpaid
+	}
+}
+
+type ReceiptParty struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone,omitempty"`
+}
+
+type ReceiptNight struct {
+	Date time.Time `json:"date"`
+	Rate float64   `json:"rate"`
+}
+
+type ReceiptTaxLine struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+type BookingReceipt struct {
+	ReceiptNumber   string           `json:"receipt_number"`
+	IssuedAt        time.Time        `json:"issued_at"`
+	BookingID       uuid.UUID        `json:"booking_id"`
+	BookedAt        time.Time        `json:"booked_at"`
+	Guest           ReceiptParty     `json:"guest"`
+	Host            ReceiptParty     `json:"host"`
+	PropertyTitle   string           `json:"property_title"`
+	PropertyAddress []string         `json:"property_address"`
+	CheckIn         time.Time        `json:"check_in"`
+	CheckOut        time.Time        `json:"check_out"`
+	Guests          int              `json:"guests"`
+	Nights          []ReceiptNight   `json:"nights"`
+	Subtotal        float64          `json:"subtotal"`
+	Taxes           []ReceiptTaxLine `json:"taxes"`
+	TaxTotal        float64          `json:"tax_total"`
+	Total           float64          `json:"total"`
+	Currency        string           `json:"currency"`
+	BookingStatus   BookingStatus    `json:"booking_status"`
+	PaymentStatus   PaymentStatus    `json:"payment_status"`
+}
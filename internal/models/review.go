@@ -50,6 +50,11 @@ func (Review) TableName() string {
 	return "reviews"
 }
 
+// PageCursor returns the position of the review in paginated lists
+func (r *Review) PageCursor() Cursor {
+	return Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+}
+
 func (r *Review) ToResponse() *ReviewResponse {
 	response := &ReviewResponse{
 		ID:         r.ID,
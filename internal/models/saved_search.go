@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SearchCriteria is a property search stored as jsonb on a saved search
+type SearchCriteria PropertySearchRequest
+
+func (c SearchCriteria) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+func (c *SearchCriteria) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// SavedSearch is a search a guest asked to be alerted about. LastRunAt is
+// when the matcher last checked it, so each run only considers listings that
+// changed afterwards.
+type SavedSearch struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
+	Name          string         `json:"name" gorm:"not null" validate:"required,max=100"`
+	Criteria      SearchCriteria `json:"criteria" gorm:"type:jsonb;not null"`
+	AlertsEnabled bool           `json:"alerts_enabled" gorm:"not null;default:true"`
+	LastRunAt     *time.Time     `json:"last_run_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+	User          User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+type SavedSearchCreateRequest struct {
+	Name          string                `json:"name" validate:"required,max=100"`
+	Criteria      PropertySearchRequest `json:"criteria"`
+	AlertsEnabled *bool                 `json:"alerts_enabled"`
+}
+
+type SavedSearchUpdateRequest struct {
+	Name          string                 `json:"name,omitempty" validate:"omitempty,max=100"`
+	Criteria      *PropertySearchRequest `json:"criteria,omitempty"`
+	AlertsEnabled *bool                  `json:"alerts_enabled,omitempty"`
+}
+
+type SavedSearchResponse struct {
+	ID            uuid.UUID             `json:"id"`
+	Name          string                `json:"name"`
+	Criteria      PropertySearchRequest `json:"criteria"`
+	AlertsEnabled bool                  `json:"alerts_enabled"`
+	LastRunAt     *time.Time            `json:"last_run_at"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
+
+// SearchRequest returns the stored criteria as a search request
+func (s *SavedSearch) SearchRequest() *PropertySearchRequest {
+	req := PropertySearchRequest(s.Criteria)
+	return &req
+}
+
+// PageCursor returns the position of the saved search in paginated lists
+func (s *SavedSearch) PageCursor() Cursor {
+	return Cursor{CreatedAt: s.CreatedAt, ID: s.ID}
+}
+
+func (s *SavedSearch) ToResponse() *SavedSearchResponse {
+	return &SavedSearchResponse{
+		ID:            s.ID,
+		Name:          s.Name,
+		Criteria:      PropertySearchRequest(s.Criteria),
+		AlertsEnabled: s.AlertsEnabled,
+		LastRunAt:     s.LastRunAt,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql/driver"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaxRuleType string
+
+const (
+	// a percentage of the nightly subtotal
+	TaxRuleTypePercentage TaxRuleType = "percentage"
+	// a flat fee charged for every night
+	TaxRuleTypePerNight TaxRuleType = "per_night"
+	// a flat fee charged for every guest on every night
+	TaxRuleTypePerGuest TaxRuleType = "per_guest"
+)
+
+// TaxRule is an occupancy tax charged by a jurisdiction. Empty State or City
+// match every state or city within the country.
+type TaxRule struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name      string         `json:"name" gorm:"not null" validate:"required"`
+	Country   string         `json:"country" gorm:"not null" validate:"required"`
+	State     string         `json:"state" gorm:"not null;default:''"`
+	City      string         `json:"city" gorm:"not null;default:''"`
+	Type      TaxRuleType    `json:"type" gorm:"type:varchar(20);not null" validate:"required,oneof=percentage per_night per_guest"`
+	Rate      float64        `json:"rate" gorm:"type:decimal(10,4);not null" validate:"min=0"`
+	MaxNights int            `json:"max_nights" gorm:"not null;default:0" validate:"min=0"`
+	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type TaxRuleCreateRequest struct {
+	Name      string      `json:"name" validate:"required"`
+	Country   string      `json:"country" validate:"required"`
+	State     string      `json:"state"`
+	City      string      `json:"city"`
+	Type      TaxRuleType `json:"type" validate:"required,oneof=percentage per_night per_guest"`
+	Rate      float64     `json:"rate" validate:"min=0"`
+	MaxNights int         `json:"max_nights" validate:"min=0"`
+	IsActive  *bool       `json:"is_active"`
+}
+
+type TaxRuleUpdateRequest struct {
+	Name      string      `json:"name,omitempty"`
+	Country   string      `json:"country,omitempty"`
+	State     *string     `json:"state,omitempty"`
+	City      *string     `json:"city,omitempty"`
+	Type      TaxRuleType `json:"type,omitempty" validate:"omitempty,oneof=percentage per_night per_guest"`
+	Rate      *float64    `json:"rate,omitempty" validate:"omitempty,min=0"`
+	MaxNights *int        `json:"max_nights,omitempty" validate:"omitempty,min=0"`
+	IsActive  *bool       `json:"is_active,omitempty"`
+}
+
+func (TaxRule) TableName() string {
+	return "tax_rules"
+}
+
+// PageCursor returns the position of the tax rule in paginated lists
+func (t *TaxRule) PageCursor() Cursor {
+	return Cursor{CreatedAt: t.CreatedAt, ID: t.ID}
+}
+
+// Amount returns the tax owed for a stay, only counting nights up to MaxNights
+func (t *TaxRule) Amount(nights int, nightlyRate float64, guests int) float64 {
+	taxedNights := nights
+	if t.MaxNights > 0 && taxedNights > t.MaxNights {
+		taxedNights = t.MaxNights
+	}
+
+	var amount float64
+	switch t.Type {
+	case TaxRuleTypePercentage:
+		amount = float64(taxedNights) * nightlyRate * t.Rate / 100
+	case TaxRuleTypePerNight:
+		amount = float64(taxedNights) * t.Rate
+	case TaxRuleTypePerGuest:
+		amount = float64(taxedNights*guests) * t.Rate
+	}
+
+	return math.Round(amount*100) / 100
+}
+
+// BookingTax is a single tax line charged on a booking
+type BookingTax struct {
+	TaxRuleID uuid.UUID   `json:"tax_rule_id"`
+	Name      string      `json:"name"`
+	Type      TaxRuleType `json:"type"`
+	Rate      float64     `json:"rate"`
+	Amount    float64     `json:"amount"`
+}
+
+// BookingTaxes is stored as jsonb on the booking
+type BookingTaxes []BookingTax
+
+func (t BookingTaxes) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	return jsonValue(t)
+}
+
+func (t *BookingTaxes) Scan(src interface{}) error {
+	return scanJSON(src, t)
+}
@@ -16,22 +16,23 @@ const (
 )
 
 type User struct {
-	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Email      string         `json:"email" gorm:"uniqueIndex;not null" validate:"required,email"`
-	Password   string         `json:"-" gorm:"not null" validate:"required,min=8"`
-	FirstName  string         `json:"first_name" gorm:"not null" validate:"required,min=2,max=50"`
-	LastName   string         `json:"last_name" gorm:"not null" validate:"required,min=2,max=50"`
-	Phone      string         `json:"phone" gorm:"unique"`
-	Avatar     string         `json:"avatar"`
-	Bio        string         `json:"bio" gorm:"type:text"`
-	Role       UserRole       `json:"role" gorm:"type:varchar(20);default:'guest'" validate:"required,oneof=guest host admin"`
-	IsActive   bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
-	Properties []Property     `json:"properties,omitempty" gorm:"foreignKey:HostID"`
-	Bookings   []Booking      `json:"bookings,omitempty" gorm:"foreignKey:GuestID"`
-	Reviews    []Review       `json:"reviews,omitempty" gorm:"foreignKey:ReviewerID"`
+	ID                   uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email                string         `json:"email" gorm:"uniqueIndex;not null" validate:"required,email"`
+	Password             string         `json:"-" gorm:"not null" validate:"required,min=8"`
+	FirstName            string         `json:"first_name" gorm:"not null" validate:"required,min=2,max=50"`
+	LastName             string         `json:"last_name" gorm:"not null" validate:"required,min=2,max=50"`
+	Phone                string         `json:"phone" gorm:"unique"`
+	Avatar               string         `json:"avatar"`
+	Bio                  string         `json:"bio" gorm:"type:text"`
+	Role                 UserRole       `json:"role" gorm:"type:varchar(20);default:'guest'" validate:"required,oneof=guest host admin"`
+	IsActive             bool           `json:"is_active" gorm:"default:true"`
+	RecentlyViewedOptOut bool           `json:"recently_viewed_opt_out" gorm:"not null;default:false"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+	Properties           []Property     `json:"properties,omitempty" gorm:"foreignKey:HostID"`
+	Bookings             []Booking      `json:"bookings,omitempty" gorm:"foreignKey:GuestID"`
+	Reviews              []Review       `json:"reviews,omitempty" gorm:"foreignKey:ReviewerID"`
 }
 
 type UserCreateRequest struct {
@@ -44,11 +45,12 @@ type UserCreateRequest struct {
 }
 
 type UserUpdateRequest struct {
-	FirstName string `json:"first_name,omitempty" validate:"omitempty,min=2,max=50"`
-	LastName  string `json:"last_name,omitempty" validate:"omitempty,min=2,max=50"`
-	Phone     string `json:"phone,omitempty"`
-	Avatar    string `json:"avatar,omitempty"`
-	Bio       string `json:"bio,omitempty"`
+	FirstName            string `json:"first_name,omitempty" validate:"omitempty,min=2,max=50"`
+	LastName             string `json:"last_name,omitempty" validate:"omitempty,min=2,max=50"`
+	Phone                string `json:"phone,omitempty"`
+	Avatar               string `json:"avatar,omitempty"`
+	Bio                  string `json:"bio,omitempty"`
+	RecentlyViewedOptOut *bool  `json:"recently_viewed_opt_out,omitempty"`
 }
 
 type UserLoginRequest struct {
@@ -57,17 +59,18 @@ type UserLoginRequest struct {
 }
 
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Phone     string    `json:"phone"`
-	Avatar    string    `json:"avatar"`
-	Bio       string    `json:"bio"`
-	Role      UserRole  `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                   uuid.UUID `json:"id"`
+	Email                string    `json:"email"`
+	FirstName            string    `json:"first_name"`
+	LastName             string    `json:"last_name"`
+	Phone                string    `json:"phone"`
+	Avatar               string    `json:"avatar"`
+	Bio                  string    `json:"bio"`
+	Role                 UserRole  `json:"role"`
+	IsActive             bool      `json:"is_active"`
+	RecentlyViewedOptOut bool      `json:"recently_viewed_opt_out"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 }
 
 func (User) TableName() string {
@@ -76,16 +79,17 @@ func (User) TableName() string {
 
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Phone:     u.Phone,
-		Avatar:    u.Avatar,
-		Bio:       u.Bio,
-		Role:      u.Role,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                   u.ID,
+		Email:                u.Email,
+		FirstName:            u.FirstName,
+		LastName:             u.LastName,
+		Phone:                u.Phone,
+		Avatar:               u.Avatar,
+		Bio:                  u.Bio,
+		Role:                 u.Role,
+		IsActive:             u.IsActive,
+		RecentlyViewedOptOut: u.RecentlyViewedOptOut,
+		CreatedAt:            u.CreatedAt,
+		UpdatedAt:            u.UpdatedAt,
 	}
 }
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WishlistVisibility string
+
+const (
+	// only the owner and collaborators can see the wishlist
+	WishlistVisibilityPrivate WishlistVisibility = "private"
+	// anyone with the share link can see the wishlist
+	WishlistVisibilityShared WishlistVisibility = "shared"
+)
+
+// Wishlist is a named list of properties kept by a guest. ShareToken is set
+// while the wishlist is shared and is the secret part of its share link.
+type Wishlist struct {
+	ID            uuid.UUID              `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	OwnerID       uuid.UUID              `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Name          string                 `json:"name" gorm:"not null" validate:"required,max=100"`
+	Visibility    WishlistVisibility     `json:"visibility" gorm:"type:varchar(20);not null;default:'private'" validate:"oneof=private shared"`
+	ShareToken    *string                `json:"-" gorm:"uniqueIndex"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt         `json:"-" gorm:"index"`
+	Items         []WishlistItem         `json:"items,omitempty" gorm:"foreignKey:WishlistID"`
+	Collaborators []WishlistCollaborator `json:"collaborators,omitempty" gorm:"foreignKey:WishlistID"`
+}
+
+// WishlistItem is a property saved to a wishlist, with optional notes and
+// the dates the guest has in mind
+type WishlistItem struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	WishlistID uuid.UUID  `json:"wishlist_id" gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_items_property"`
+	PropertyID uuid.UUID  `json:"property_id" gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_items_property"`
+	AddedByID  uuid.UUID  `json:"added_by_id" gorm:"type:uuid;not null"`
+	Notes      string     `json:"notes" gorm:"type:text"`
+	CheckIn    *time.Time `json:"check_in"`
+	CheckOut   *time.Time `json:"check_out"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Property   Property   `json:"property,omitempty" gorm:"foreignKey:PropertyID"`
+}
+
+// WishlistCollaborator is a user the owner allowed to add items to a wishlist
+type WishlistCollaborator struct {
+	WishlistID uuid.UUID `json:"wishlist_id" gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt  time.Time `json:"created_at"`
+	User       User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+type WishlistCreateRequest struct {
+	Name       string             `json:"name" validate:"required,max=100"`
+	Visibility WishlistVisibility `json:"visibility" validate:"omitempty,oneof=private shared"`
+}
+
+type WishlistUpdateRequest struct {
+	Name       string             `json:"name,omitempty" validate:"omitempty,max=100"`
+	Visibility WishlistVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=private shared"`
+}
+
+type WishlistItemCreateRequest struct {
+	PropertyID uuid.UUID  `json:"property_id" validate:"required"`
+	Notes      string     `json:"notes"`
+	CheckIn    *time.Time `json:"check_in"`
+	CheckOut   *time.Time `json:"check_out"`
+}
+
+type WishlistItemUpdateRequest struct {
+	Notes    *string    `json:"notes,omitempty"`
+	CheckIn  *time.Time `json:"check_in,omitempty"`
+	CheckOut *time.Time `json:"check_out,omitempty"`
+}
+
+type WishlistCollaboratorRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type WishlistResponse struct {
+	ID            uuid.UUID               `json:"id"`
+	OwnerID       uuid.UUID               `json:"owner_id"`
+	Name          string                  `json:"name"`
+	Visibility    WishlistVisibility      `json:"visibility"`
+	ShareToken    string                  `json:"share_token,omitempty"`
+	ItemCount     int                     `json:"item_count"`
+	Items         []*WishlistItemResponse `json:"items,omitempty"`
+	Collaborators []*UserResponse         `json:"collaborators,omitempty"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+}
+
+type WishlistItemResponse struct {
+	ID         uuid.UUID         `json:"id"`
+	PropertyID uuid.UUID         `json:"property_id"`
+	AddedByID  *uuid.UUID        `json:"added_by_id,omitempty"`
+	Notes      *string           `json:"notes,omitempty"`
+	CheckIn    *time.Time        `json:"check_in"`
+	CheckOut   *time.Time        `json:"check_out"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Property   *PropertyResponse `json:"property,omitempty"`
+}
+
+func (Wishlist) TableName() string {
+	return "wishlists"
+}
+
+func (WishlistItem) TableName() string {
+	return "wishlist_items"
+}
+
+func (WishlistCollaborator) TableName() string {
+	return "wishlist_collaborators"
+}
+
+// PageCursor returns the position of the wishlist in paginated lists
+func (w *Wishlist) PageCursor() Cursor {
+	return Cursor{CreatedAt: w.CreatedAt, ID: w.ID}
+}
+
+// converts Wishlist to WishlistResponse. The share token and collaborators are
+// only included for members, not for viewers of a share link, who only see
+// the items whose listing is active, without their notes or who added them.
+func (w *Wishlist) ToResponse(forMember bool) *WishlistResponse {
+	response := &WishlistResponse{
+		ID:         w.ID,
+		OwnerID:    w.OwnerID,
+		Name:       w.Name,
+		Visibility: w.Visibility,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+
+	if forMember && w.ShareToken != nil {
+		response.ShareToken = *w.ShareToken
+	}
+
+	for i := range w.Items {
+		if !forMember && w.Items[i].Property.Status != PropertyStatusActive {
+			continue
+		}
+		response.Items = append(response.Items, w.Items[i].ToResponse(forMember))
+	}
+	response.ItemCount = len(response.Items)
+
+	if forMember {
+		for _, collaborator := range w.Collaborators {
+			if collaborator.User.ID != uuid.Nil {
+				response.Collaborators = append(response.Collaborators, collaborator.User.ToResponse())
+			}
+		}
+	}
+
+	return response
+}
+
+// converts WishlistItem to WishlistItemResponse. Notes and who added the item
+// are only included for members.
+func (i *WishlistItem) ToResponse(forMember bool) *WishlistItemResponse {
+	response := &WishlistItemResponse{
+		ID:         i.ID,
+		PropertyID: i.PropertyID,
+		CheckIn:    i.CheckIn,
+		CheckOut:   i.CheckOut,
+		CreatedAt:  i.CreatedAt,
+	}
+
+	if forMember {
+		response.AddedByID = &i.AddedByID
+		response.Notes = &i.Notes
+	}
+
+	if i.Property.ID != uuid.Nil {
+		response.Property = i.Property.ToResponse()
+	}
+
+	return response
+}
@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"airbnb-clone/internal/config"
+	"airbnb-clone/internal/logger"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// notification types
+const (
+	TypeSavedSearchMatch = "saved_search_match"
+	TypeListingApproved  = "listing_approved"
+	TypeListingRejected  = "listing_rejected"
+	TypeCoHostInvitation = "cohost_invitation"
+)
+
+// Notification is a message for a single user. Data carries the structured
+// payload for channels that render their own content.
+type Notification struct {
+	Type    string
+	UserID  uuid.UUID
+	Email   string
+	Subject string
+	Body    string
+	Data    map[string]interface{}
+}
+
+// Notifier delivers notifications to users. Implementations decide the
+// channel, such as email or push.
+type Notifier interface {
+	Notify(n *Notification) error
+	// Delivers reports whether notifications reach the users rather than
+	// only being recorded
+	Delivers() bool
+}
+
+// creates the notifier selected by the configuration
+func New(cfg config.NotificationConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "smtp":
+		return NewSMTPNotifier(SMTPOptions{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	default:
+		return nil, fmt.Errorf("unknown notification driver %q", cfg.Driver)
+	}
+}
+
+// LogNotifier writes notifications to the application log. It is the default
+// notifier until a delivery channel is configured.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a notifier that logs every notification
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(notification *Notification) error {
+	logger.WithFieldsMap(map[string]interface{}{
+		"type":    notification.Type,
+		"user_id": notification.UserID,
+		"email":   notification.Email,
+	}).Infof("notification: %s", notification.Subject)
+	return nil
+}
+
+// Delivers is false, nobody reads the log on behalf of the users
+func (n *LogNotifier) Delivers() bool {
+	return false
+}
@@ -0,0 +1,150 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// how long sending one email may take, connecting included. Well below the
+// write timeout of the server, so the request that sent it can still answer.
+const smtpTimeout = 10 * time.Second
+
+// SMTPOptions configures an SMTPNotifier
+type SMTPOptions struct {
+	Host string
+	// defaults to 587, the submission port
+	Port string
+	// no authentication when empty
+	Username string
+	Password string
+	// the sender, e.g. "Airbnb Clone <no-reply@example.com>"
+	From string
+}
+
+// SMTPNotifier emails notifications through an SMTP server. The connection
+// is upgraded with STARTTLS when the server offers it, and credentials are
+// only sent over TLS or to localhost.
+type SMTPNotifier struct {
+	opts SMTPOptions
+	from *mail.Address
+}
+
+// NewSMTPNotifier creates a notifier that emails every notification to its
+// Email
+func NewSMTPNotifier(opts SMTPOptions) (*SMTPNotifier, error) {
+	if opts.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	if opts.Port == "" {
+		opts.Port = "587"
+	}
+
+	from, err := mail.ParseAddress(opts.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp from address %q: %w", opts.From, err)
+	}
+
+	return &SMTPNotifier{opts: opts, from: from}, nil
+}
+
+func (n *SMTPNotifier) Notify(notification *Notification) error {
+	to, err := mail.ParseAddress(notification.Email)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", notification.Email, err)
+	}
+
+	message, err := n.message(to, notification)
+	if err != nil {
+		return err
+	}
+
+	return n.send(to.Address, message)
+}
+
+// Delivers is true, every notification is emailed
+func (n *SMTPNotifier) Delivers() bool {
+	return true
+}
+
+// builds a plain text email with the subject and body of the notification
+func (n *SMTPNotifier) message(to *mail.Address, notification *Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	// encoded words never contain a line break, so the subject cannot add headers
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(notification.Body)); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sends the message like smtp.SendMail, but within smtpTimeout so a stalled
+// server cannot hold the request that triggered the notification
+func (n *SMTPNotifier) send(to string, message []byte) error {
+	deadline := time.Now().Add(smtpTimeout)
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(n.opts.Host, n.opts.Port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.opts.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.opts.Host}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if n.opts.Username != "" {
+		// PlainAuth refuses to send the password unencrypted to other hosts
+		auth := smtp.PlainAuth("", n.opts.Username, n.opts.Password, n.opts.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate with smtp server: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,144 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEscapePDFText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "Total 120.00 USD", want: "Total 120.00 USD"},
+		{name: "parentheses", in: "Tax (8%)", want: `Tax \(8%\)`},
+		{name: "unbalanced parenthesis", in: "1) cleaning", want: `1\) cleaning`},
+		{name: "backslash", in: `C:\receipts`, want: `C:\\receipts`},
+		{name: "control characters", in: "a\tb\nc", want: "a b c"},
+		{name: "latin-1", in: "Café São Paulo", want: `Caf\351 S\343o Paulo`},
+		{name: "outside latin-1", in: "€ 東京", want: "? ??"},
+		{name: "empty", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapePDFText(tt.in); got != tt.want {
+				t.Errorf("escapePDFText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		pages int
+	}{
+		{name: "empty", lines: nil, pages: 1},
+		{name: "one page", lines: []string{"Receipt RCPT-1234", "Tax (8%)  9.60", `C:\ Café`}, pages: 1},
+		{name: "full page", lines: numberedLines(linesPerPage), pages: 1},
+		{name: "two pages", lines: numberedLines(linesPerPage + 1), pages: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf := writePDF(tt.lines)
+
+			if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) {
+				t.Fatalf("missing PDF header: %q", pdf[:min(len(pdf), 16)])
+			}
+			if !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+				t.Errorf("missing EOF marker")
+			}
+
+			offsets := xrefOffsets(t, pdf)
+			// catalog, page tree and font, then a page and its content per page
+			if want := 3 + 2*tt.pages; len(offsets) != want {
+				t.Fatalf("xref has %d objects, want %d", len(offsets), want)
+			}
+			for i, offset := range offsets {
+				header := fmt.Sprintf("%d 0 obj\n", i+1)
+				if offset >= len(pdf) || !bytes.HasPrefix(pdf[offset:], []byte(header)) {
+					t.Errorf("xref offset %d of object %d does not point at %q", offset, i+1, header)
+				}
+			}
+
+			if count := fmt.Sprintf("/Count %d", tt.pages); !bytes.Contains(pdf, []byte(count)) {
+				t.Errorf("page tree does not have %s", count)
+			}
+			checkStreamLengths(t, pdf)
+
+			for _, line := range tt.lines {
+				shown := "(" + escapePDFText(line) + ") Tj"
+				if !bytes.Contains(pdf, []byte(shown)) {
+					t.Errorf("line %q is not shown", line)
+				}
+			}
+		})
+	}
+}
+
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+// reads the xref table startxref points at and returns the offsets of the
+// objects in use, by object number
+func xrefOffsets(t *testing.T, pdf []byte) []int {
+	t.Helper()
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if match == nil {
+		t.Fatal("missing startxref")
+	}
+	start, _ := strconv.Atoi(string(match[1]))
+	if start >= len(pdf) || !bytes.HasPrefix(pdf[start:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", start)
+	}
+
+	lines := strings.Split(string(pdf[start:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil || first != 0 {
+		t.Fatalf("invalid xref subsection %q", lines[1])
+	}
+	if lines[2] != "0000000000 65535 f " {
+		t.Errorf("object 0 entry = %q, want the free list head", lines[2])
+	}
+
+	offsets := make([]int, count-1)
+	for i := range offsets {
+		entry := lines[3+i]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("invalid xref entry %q", entry)
+		}
+		offsets[i], _ = strconv.Atoi(entry[:10])
+	}
+	if trailer := fmt.Sprintf("/Size %d", count); !bytes.Contains(pdf, []byte(trailer)) {
+		t.Errorf("trailer does not have %s", trailer)
+	}
+	return offsets
+}
+
+// checks that every stream is as long as its /Length says
+func checkStreamLengths(t *testing.T, pdf []byte) {
+	t.Helper()
+
+	streams := regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(pdf, -1)
+	if len(streams) == 0 {
+		t.Fatal("no content streams")
+	}
+	for _, stream := range streams {
+		length, _ := strconv.Atoi(string(stream[1]))
+		if length != len(stream[2]) {
+			t.Errorf("stream /Length %d, but it has %d bytes", length, len(stream[2]))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package receipt
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"airbnb-clone/internal/models"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// width of a line in the plain-text layout used for the PDF variant
+const lineWidth = 64
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("receipt.html.tmpl").
+			Funcs(htmltemplate.FuncMap{"date": formatDate, "money": formatMoney}).
+			ParseFS(templateFS, "templates/receipt.html.tmpl"))
+
+	textTemplate = texttemplate.Must(texttemplate.New("receipt.txt.tmpl").
+			Funcs(texttemplate.FuncMap{"date": formatDate, "money": formatMoney, "row": formatRow, "rule": formatRule, "plural": plural}).
+			ParseFS(templateFS, "templates/receipt.txt.tmpl"))
+)
+
+// RenderHTML renders the receipt as a standalone HTML document
+func RenderHTML(r *models.BookingReceipt) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render receipt html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPDF renders the receipt as a PDF document
+func RenderPDF(r *models.BookingReceipt) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := textTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render receipt text: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	return writePDF(lines), nil
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("Jan 2, 2006")
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// formatRow left-aligns the label and right-aligns the value on a single line
+func formatRow(label, value string) string {
+	gap := lineWidth - len(label) - len(value)
+	if gap < 1 {
+		gap = 1
+	}
+	return label + strings.Repeat(" ", gap) + value
+}
+
+func formatRule() string {
+	return strings.Repeat("-", lineWidth)
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
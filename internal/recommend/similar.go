@@ -0,0 +1,201 @@
+// Package recommend scores how similar listings are to each other. It works
+// on plain values so it can be used and tested without a database.
+package recommend
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Listing is the part of a property the similarity score looks at
+type Listing struct {
+	ID            uuid.UUID
+	Type          string
+	PricePerNight float64
+	MaxGuests     int
+	Bedrooms      int
+	Amenities     []string
+	Latitude      float64
+	Longitude     float64
+	AverageRating float64
+	ReviewCount   int
+}
+
+// Weights sets how much each signal counts towards the score. They are
+// relative to each other and do not need to add up to one.
+type Weights struct {
+	Type      float64
+	Price     float64
+	Capacity  float64
+	Amenities float64
+	Distance  float64
+	Rating    float64
+}
+
+// DefaultWeights favours listings of the same kind and price nearby
+var DefaultWeights = Weights{
+	Type:      2,
+	Price:     2,
+	Capacity:  1.5,
+	Amenities: 2,
+	Distance:  1.5,
+	Rating:    1,
+}
+
+// Options tunes the scoring
+type Options struct {
+	Weights Weights
+	// candidates this far away or further get no distance score
+	MaxDistanceKm float64
+	// relative price difference at which the price score reaches zero
+	PriceBand float64
+}
+
+// DefaultOptions scores neighbours within 50 km and a price band of ±50%
+var DefaultOptions = Options{
+	Weights:       DefaultWeights,
+	MaxDistanceKm: 50,
+	PriceBand:     0.5,
+}
+
+// Match is a candidate with its similarity to the target listing
+type Match struct {
+	Listing    Listing
+	Score      float64
+	DistanceKm float64
+}
+
+// Score returns how similar candidate is to target, between 0 and 1
+func Score(target, candidate Listing, opts Options) Match {
+	distance := HaversineKm(target.Latitude, target.Longitude, candidate.Latitude, candidate.Longitude)
+
+	w := opts.Weights
+	total := w.Type + w.Price + w.Capacity + w.Amenities + w.Distance + w.Rating
+	if total == 0 {
+		return Match{Listing: candidate, DistanceKm: distance}
+	}
+
+	score := w.Type*typeScore(target, candidate) +
+		w.Price*priceScore(target.PricePerNight, candidate.PricePerNight, opts.PriceBand) +
+		w.Capacity*capacityScore(target, candidate) +
+		w.Amenities*Jaccard(target.Amenities, candidate.Amenities) +
+		w.Distance*distanceScore(distance, opts.MaxDistanceKm) +
+		w.Rating*ratingScore(candidate)
+
+	return Match{Listing: candidate, Score: score / total, DistanceKm: distance}
+}
+
+// Rank scores the candidates against target and returns the best limit
+// matches, most similar first. The target itself is never included.
+func Rank(target Listing, candidates []Listing, opts Options, limit int) []Match {
+	matches := make([]Match, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.ID == target.ID {
+			continue
+		}
+		matches = append(matches, Score(target, candidate, opts))
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].DistanceKm < matches[j].DistanceKm
+	})
+
+	if limit >= 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Jaccard returns the size of the intersection of a and b over the size of
+// their union, ignoring case and duplicates. Two empty sets score zero since
+// they say nothing about similarity.
+func Jaccard(a, b []string) float64 {
+	setA := toSet(a)
+	setB := toSet(b)
+	if len(setA) == 0 && len(setB) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for value := range setA {
+		if setB[value] {
+			intersection++
+		}
+	}
+	union := len(setA) + len(setB) - intersection
+
+	return float64(intersection) / float64(union)
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
+
+const earthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance between two points in km
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func typeScore(target, candidate Listing) float64 {
+	if target.Type != "" && target.Type == candidate.Type {
+		return 1
+	}
+	return 0
+}
+
+// falls linearly from 1 at the same price to 0 at band away, relative to the
+// target price
+func priceScore(target, candidate, band float64) float64 {
+	if target <= 0 || band <= 0 {
+		return 0
+	}
+	return clamp01(1 - math.Abs(candidate-target)/(target*band))
+}
+
+// averages how close the guest and bedroom counts are
+func capacityScore(target, candidate Listing) float64 {
+	guests := 1 - math.Abs(float64(candidate.MaxGuests-target.MaxGuests))/math.Max(float64(target.MaxGuests), 1)
+	bedrooms := 1 - math.Abs(float64(candidate.Bedrooms-target.Bedrooms))/math.Max(float64(target.Bedrooms), 1)
+	return (clamp01(guests) + clamp01(bedrooms)) / 2
+}
+
+func distanceScore(distanceKm, maxDistanceKm float64) float64 {
+	if maxDistanceKm <= 0 {
+		return 0
+	}
+	return clamp01(1 - distanceKm/maxDistanceKm)
+}
+
+// unreviewed listings get a neutral score rather than the lowest one
+func ratingScore(candidate Listing) float64 {
+	if candidate.ReviewCount == 0 {
+		return 0.5
+	}
+	return clamp01(candidate.AverageRating / 5)
+}
+
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
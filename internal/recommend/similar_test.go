@@ -0,0 +1,108 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want float64
+	}{
+		{"both empty", nil, []string{}, 0},
+		{"one empty", []string{"wifi"}, nil, 0},
+		{"identical", []string{"wifi", "pool"}, []string{"pool", "wifi"}, 1},
+		{"duplicates and case", []string{"wifi", "WiFi", " wifi "}, []string{"wifi"}, 1},
+		{"partial overlap", []string{"wifi", "pool", "gym"}, []string{"wifi", "pool", "parking"}, 0.5},
+		{"blank values ignored", []string{"", " "}, []string{""}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Jaccard(tt.a, tt.b); got != tt.want {
+				t.Errorf("Jaccard(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceScore(t *testing.T) {
+	tests := []struct {
+		name                    string
+		target, candidate, band float64
+		want                    float64
+	}{
+		{"same price", 100, 100, 0.5, 1},
+		{"half way into the band", 100, 125, 0.5, 0.5},
+		{"cheaper half way into the band", 100, 75, 0.5, 0.5},
+		{"at the band edge", 100, 150, 0.5, 0},
+		{"beyond the band", 100, 300, 0.5, 0},
+		{"free target", 0, 100, 0.5, 0},
+		{"no band", 100, 100, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priceScore(tt.target, tt.candidate, tt.band); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("priceScore(%v, %v, %v) = %v, want %v", tt.target, tt.candidate, tt.band, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	target := Listing{ID: uuid.New(), Type: "apartment", PricePerNight: 100, MaxGuests: 2, Bedrooms: 1}
+	closest := Listing{ID: uuid.New(), Type: "apartment", PricePerNight: 100, MaxGuests: 2, Bedrooms: 1}
+	similar := Listing{ID: uuid.New(), Type: "apartment", PricePerNight: 120, MaxGuests: 2, Bedrooms: 1}
+	far := Listing{ID: uuid.New(), Type: "villa", PricePerNight: 900, MaxGuests: 12, Bedrooms: 6}
+	candidates := []Listing{far, target, similar, closest}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []uuid.UUID
+	}{
+		{"all but the target", 10, []uuid.UUID{closest.ID, similar.ID, far.ID}},
+		{"limited", 2, []uuid.UUID{closest.ID, similar.ID}},
+		{"zero limit", 0, []uuid.UUID{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := Rank(target, candidates, DefaultOptions, tt.limit)
+			if len(matches) != len(tt.want) {
+				t.Fatalf("Rank returned %d matches, want %d", len(matches), len(tt.want))
+			}
+			for i, match := range matches {
+				if match.Listing.ID != tt.want[i] {
+					t.Errorf("match %d is %s, want %s", i, match.Listing.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want, tolerance        float64
+	}{
+		{"same point", 48.8566, 2.3522, 48.8566, 2.3522, 0, 1e-9},
+		{"Paris to London", 48.8566, 2.3522, 51.5074, -0.1278, 343.5, 1},
+		{"one degree of longitude on the equator", 0, 0, 0, 1, 111.19, 0.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HaversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("HaversineKm = %v, want %v ± %v", got, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
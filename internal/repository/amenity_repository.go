@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// the SQL counterpart of models.AmenityKey
+const amenityKeySQL = "regexp_replace(lower(%s), '[^[:alnum:]]', '', 'g')"
+
+type amenityRepository struct {
+	db *gorm.DB
+}
+
+func NewAmenityRepository(db *gorm.DB) AmenityRepository {
+	return &amenityRepository{db: db}
+}
+
+func (r *amenityRepository) CreateAmenity(amenity *models.Amenity) error {
+	return r.db.Create(amenity).Error
+}
+
+func (r *amenityRepository) GetAmenityByID(id string) (*models.Amenity, error) {
+	var amenity models.Amenity
+	err := r.db.Where("id = ?", id).First(&amenity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &amenity, nil
+}
+
+func (r *amenityRepository) UpdateAmenity(amenity *models.Amenity) error {
+	return r.db.Save(amenity).Error
+}
+
+func (r *amenityRepository) DeleteAmenity(id string) error {
+	return r.db.Delete(&models.Amenity{}, "id = ?", id).Error
+}
+
+// returns the whole catalog ordered for display
+func (r *amenityRepository) ListAmenities() ([]*models.Amenity, error) {
+	var amenities []*models.Amenity
+	err := r.db.Order("category, id").Find(&amenities).Error
+	return amenities, err
+}
+
+// counts the listings, including deleted ones, that still list the amenity
+func (r *amenityRepository) CountPropertiesWithAmenity(id string) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Property{}).
+		Where("amenities @> ARRAY[?]::text[]", id).
+		Count(&count).Error
+	return count, err
+}
+
+// RemapPropertyAmenities rewrites the amenities of listings to catalog ids,
+// matching on ids and aliases in any spelling. Values that match nothing are
+// moved to unmapped_amenities and mapped once the catalog covers them. It
+// returns the number of listings changed.
+func (r *amenityRepository) RemapPropertyAmenities() (int64, error) {
+	matches := fmt.Sprintf("(%s = %s OR %s = ANY(amenities.aliases))",
+		fmt.Sprintf(amenityKeySQL, "value"), fmt.Sprintf(amenityKeySQL, "amenities.id"), fmt.Sprintf(amenityKeySQL, "value"))
+
+	result := r.db.Exec(fmt.Sprintf(`
+		UPDATE properties SET
+			amenities = COALESCE((
+				SELECT array_agg(DISTINCT amenities.id ORDER BY amenities.id)
+				FROM unnest(properties.amenities || properties.unmapped_amenities) AS value
+				JOIN amenities ON %[1]s
+			), '{}'),
+			unmapped_amenities = COALESCE((
+				SELECT array_agg(DISTINCT value ORDER BY value)
+				FROM unnest(properties.amenities || properties.unmapped_amenities) AS value
+				WHERE NOT EXISTS (SELECT 1 FROM amenities WHERE %[1]s)
+			), '{}')
+		WHERE EXISTS (SELECT 1 FROM unnest(properties.amenities) AS value WHERE value NOT IN (SELECT id FROM amenities))
+			OR EXISTS (SELECT 1 FROM unnest(properties.unmapped_amenities) AS value JOIN amenities ON %[1]s)
+	`, matches))
+	return result.RowsAffected, result.Error
+}
+
+// returns the unmapped amenities of live listings, the most used first
+func (r *amenityRepository) GetUnmappedAmenities() ([]*models.UnmappedAmenity, error) {
+	var unmapped []*models.UnmappedAmenity
+	err := r.db.Raw(`
+		SELECT value, COUNT(*) AS property_count
+		FROM properties, unnest(properties.unmapped_amenities) AS value
+		WHERE properties.deleted_at IS NULL
+		GROUP BY value
+		ORDER BY property_count DESC, value
+	`).Scan(&unmapped).Error
+	return unmapped, err
+}
@@ -44,21 +44,19 @@ func (r *bookingRepository) GetBookingByID(id uuid.UUID) (*models.Booking, error
 	return &booking, nil
 }
 
-func (r *bookingRepository) GetBookingByUserID(userID uuid.UUID, offset, limit int) ([]*models.Booking, error) {
+func (r *bookingRepository) GetBookingByUserID(userID uuid.UUID, page models.PageQuery) ([]*models.Booking, error) {
 	var bookings []*models.Booking
-	err := r.db.Preload("Property").Preload("Guest").
-		Where("guest_id = ?", userID).
-		Order("created_at DESC").
-		Offset(offset).Limit(limit).Find(&bookings).Error
+	query := r.db.Preload("Property").Preload("Guest").
+		Where("guest_id = ?", userID)
+	err := paginate(query, "bookings", page).Find(&bookings).Error
 	return bookings, err
 }
 
-func (r *bookingRepository) GetBookingByPropertyID(propertyID uuid.UUID, offset, limit int) ([]*models.Booking, error) {
+func (r *bookingRepository) GetBookingByPropertyID(propertyID uuid.UUID, page models.PageQuery) ([]*models.Booking, error) {
 	var bookings []*models.Booking
-	err := r.db.Preload("Property").Preload("Guest").
-		Where("property_id = ?", propertyID).
-		Order("created_at DESC").
-		Offset(offset).Limit(limit).Find(&bookings).Error
+	query := r.db.Preload("Property").Preload("Guest").
+		Where("property_id = ?", propertyID)
+	err := paginate(query, "bookings", page).Find(&bookings).Error
 	return bookings, err
 }
 
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"time"
+
+	"airbnb-clone/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type coHostRepository struct {
+	db *gorm.DB
+}
+
+func NewCoHostRepository(db *gorm.DB) CoHostRepository {
+	return &coHostRepository{db: db}
+}
+
+func (r *coHostRepository) GetCoHost(propertyID, userID uuid.UUID) (*models.PropertyCoHost, error) {
+	var cohost models.PropertyCoHost
+	err := r.db.Where("property_id = ? AND user_id = ?", propertyID, userID).First(&cohost).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cohost, nil
+}
+
+func (r *coHostRepository) GetCoHostsByPropertyID(propertyID uuid.UUID) ([]*models.PropertyCoHost, error) {
+	var cohosts []*models.PropertyCoHost
+	err := r.db.Preload("User").Where("property_id = ?", propertyID).Order("created_at").Find(&cohosts).Error
+	return cohosts, err
+}
+
+func (r *coHostRepository) UpdateCoHostPermissions(propertyID, userID uuid.UUID, permissions []string) error {
+	result := r.db.Model(&models.PropertyCoHost{}).
+		Where("property_id = ? AND user_id = ?", propertyID, userID).
+		Updates(map[string]interface{}{"permissions": pq.StringArray(permissions), "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *coHostRepository) RemoveCoHost(propertyID, userID uuid.UUID) error {
+	result := r.db.Where("property_id = ? AND user_id = ?", propertyID, userID).Delete(&models.PropertyCoHost{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// saves the invitation, replacing any pending one of the property to the
+// same email so only the latest link works
+func (r *coHostRepository) CreateInvitation(invitation *models.CoHostInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("property_id = ? AND email = ? AND accepted_at IS NULL", invitation.PropertyID, invitation.Email).
+			Delete(&models.CoHostInvitation{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Create(invitation).Error
+	})
+}
+
+func (r *coHostRepository) GetInvitationByTokenHash(tokenHash string) (*models.CoHostInvitation, error) {
+	var invitation models.CoHostInvitation
+	err := r.db.Preload("Property").Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// returns the invitations of the property that can still be accepted
+func (r *coHostRepository) GetPendingInvitations(propertyID uuid.UUID) ([]*models.CoHostInvitation, error) {
+	var invitations []*models.CoHostInvitation
+	err := r.db.Where("property_id = ? AND accepted_at IS NULL AND expires_at > ?", propertyID, time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *coHostRepository) DeleteInvitation(propertyID, invitationID uuid.UUID) error {
+	result := r.db.Where("id = ? AND property_id = ? AND accepted_at IS NULL", invitationID, propertyID).
+		Delete(&models.CoHostInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// marks the invitation accepted and adds the co-host, or gives an existing
+// co-host the permissions of the invitation. Returns gorm.ErrRecordNotFound
+// when the invitation was accepted in the meantime.
+func (r *coHostRepository) AcceptInvitation(invitation *models.CoHostInvitation, cohost *models.PropertyCoHost) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.CoHostInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", invitation.AcceptedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "property_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"permissions", "invited_by_id", "updated_at"}),
+		}).Create(cohost).Error
+	})
+}
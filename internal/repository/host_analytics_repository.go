@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"airbnb-clone/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The aggregations take the period as the dates from start up to, but not
+// including, end.
+
+type hostAnalyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewHostAnalyticsRepository(db *gorm.DB) HostAnalyticsRepository {
+	return &hostAnalyticsRepository{db: db}
+}
+
+// returns the properties the user hosts or co-hosts with the permission
+func (r *hostAnalyticsRepository) GetManagedPropertyIDs(userID uuid.UUID, permission models.CoHostPermission) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&models.Property{}).
+		Where("host_id = ? OR id IN (SELECT property_id FROM property_cohosts WHERE user_id = ? AND ? = ANY(permissions))", userID, userID, string(permission)).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// counts the nights the properties were live during the period, from their
+// approval on, and how many of those confirmed and completed stays booked.
+// Listings never approved were never bookable. Inactive ones are left out as
+// well, when they were taken down is not kept. No property counts more booked
+// nights than it had available.
+func (r *hostAnalyticsRepository) GetOccupancy(propertyIDs []uuid.UUID, start, end time.Time) (*models.Occupancy, error) {
+	occupancy := &models.Occupancy{}
+	query := `
+		SELECT COALESCE(SUM(available), 0) AS available_nights,
+			COALESCE(SUM(LEAST(booked, available)), 0) AS booked_nights
+		FROM (
+			SELECT GREATEST(0, @end::date - GREATEST(@start::date, p.approved_at::date)) AS available,
+				COALESCE((
+					SELECT SUM(GREATEST(0, LEAST(b.check_out::date, @end::date) - GREATEST(b.check_in::date, @start::date, p.approved_at::date)))
+					FROM bookings b
+					WHERE b.property_id = p.id AND b.deleted_at IS NULL
+						AND b.status IN ('confirmed', 'completed')
+						AND b.check_in::date < @end::date AND b.check_out::date > @start::date
+				), 0) AS booked
+			FROM properties p
+			WHERE p.id IN @ids AND p.approved_at IS NOT NULL AND p.status <> @inactive
+		) live
+	`
+	err := r.db.Raw(query,
+		sql.Named("start", start),
+		sql.Named("end", end),
+		sql.Named("ids", propertyIDs),
+		sql.Named("inactive", models.PropertyStatusInactive),
+	).Scan(occupancy).Error
+	return occupancy, err
+}
+
+// sums the nights and revenue of confirmed and completed stays per currency.
+// Stays running past either end of the period count the nights inside it and
+// that share of their price before taxes.
+func (r *hostAnalyticsRepository) GetRevenue(propertyIDs []uuid.UUID, start, end time.Time) ([]*models.CurrencyRevenue, error) {
+	var revenue []*models.CurrencyRevenue
+	query := `
+		SELECT currency, SUM(nights) AS booked_nights, SUM(amount * nights / stay_nights) AS revenue
+		FROM (
+			SELECT COALESCE(NULLIF(currency, ''), 'USD') AS currency,
+				LEAST(check_out::date, ?::date) - GREATEST(check_in::date, ?::date) AS nights,
+				GREATEST(check_out::date - check_in::date, 1) AS stay_nights,
+				CASE WHEN subtotal > 0 THEN subtotal ELSE total_price - tax_amount END AS amount
+			FROM bookings
+			WHERE property_id IN ? AND deleted_at IS NULL
+				AND status IN ('confirmed', 'completed')
+				AND check_in::date < ?::date AND check_out::date > ?::date
+		) stays
+		GROUP BY currency
+		ORDER BY revenue DESC
+	`
+	err := r.db.Raw(query, end, start, propertyIDs, end, start).Scan(&revenue).Error
+	return revenue, err
+}
+
+// counts the bookings made during the period and how far ahead they were made
+func (r *hostAnalyticsRepository) GetBookingActivity(propertyIDs []uuid.UUID, start, end time.Time) (*models.BookingActivity, error) {
+	activity := &models.BookingActivity{}
+	query := `
+		SELECT COUNT(*) AS bookings,
+			COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled_bookings,
+			COALESCE(AVG(check_in::date - created_at::date) FILTER (WHERE status <> 'cancelled'), 0) AS average_lead_days
+		FROM bookings
+		WHERE property_id IN ? AND deleted_at IS NULL
+			AND created_at >= ? AND created_at < ?
+	`
+	err := r.db.Raw(query, propertyIDs, start, end).Scan(activity).Error
+	return activity, err
+}
+
+// averages the ratings of the reviews left during the period per month
+func (r *hostAnalyticsRepository) GetRatingTrend(propertyIDs []uuid.UUID, start, end time.Time) ([]*models.RatingTrendPoint, error) {
+	var trend []*models.RatingTrendPoint
+	query := `
+		SELECT date_trunc('month', created_at) AS month, AVG(rating) AS average_rating, COUNT(*) AS review_count
+		FROM reviews
+		WHERE property_id IN ? AND deleted_at IS NULL
+			AND created_at >= ? AND created_at < ?
+		GROUP BY month
+		ORDER BY month
+	`
+	err := r.db.Raw(query, propertyIDs, start, end).Scan(&trend).Error
+	return trend, err
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"airbnb-clone/internal/models"
 
 	"github.com/google/uuid"
@@ -10,26 +12,32 @@ type UserRepository interface {
 	CreateUser(user *models.User) error
 	GetUserByID(id uuid.UUID) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
+	UpdateUser(user *models.User) error
 	
 }
 
 type PropertyRepository interface {
-	Create(property *models.Property) error
+	Create(property *models.Property, revision *models.PropertyRevision) error
 	GetPropertyByID(id uuid.UUID) (*models.Property, error) 
-	UpdateProperty(property *models.Property) error
+	UpdateProperty(property *models.Property, pending *models.PropertyChanges, revision *models.PropertyRevision) (bool, error)
 	DeleteProperty(id uuid.UUID) error 
-	ListProperties(offset, limit int) ([]*models.Property, error)
+	ListProperties(page models.PageQuery) ([]*models.Property, error)
 	SearchProperties(req *models.PropertySearchRequest) ([]*models.Property, int64, error) 
-	GetPropertiesByHostID(hostID uuid.UUID, offset, limit int) ([]*models.Property, error)
+	SearchFacets(req *models.PropertySearchRequest) (*models.SearchFacets, error)
+	GetActivePropertiesByIDs(ids []uuid.UUID) ([]*models.Property, error)
+	GetSimilarCandidates(property *models.Property, radiusKm float64, checkIn, checkOut time.Time, limit int) ([]*models.Property, error)
+	SuggestDestinations(prefix string, limit int) ([]*models.DestinationSuggestion, error)
+	GetPropertiesByHostID(hostID uuid.UUID, page models.PageQuery) ([]*models.Property, error)
 	CheckAvailability(propertyID uuid.UUID, checkIn, checkOut string) (bool, error)
+	UpdateRatingSummary(propertyID uuid.UUID) error
 }
 
 type BookingRepository interface {
 	GetConflictingBookings(propertyID uuid.UUID, checkIn, checkOut string) ([]*models.Booking, error)
 	CreateBooking(booking *models.Booking) error 
 	GetBookingByID(id uuid.UUID) (*models.Booking, error)
-	GetBookingByUserID(userID uuid.UUID, offset, limit int) ([]*models.Booking, error)
-	GetBookingByPropertyID(propertyID uuid.UUID, offset, limit int) ([]*models.Booking, error)
+	GetBookingByUserID(userID uuid.UUID, page models.PageQuery) ([]*models.Booking, error)
+	GetBookingByPropertyID(propertyID uuid.UUID, page models.PageQuery) ([]*models.Booking, error)
 	UpdateBooking(booking *models.Booking) error
 	DeleteBooking(id uuid.UUID) error
 }
@@ -37,11 +45,110 @@ type BookingRepository interface {
 type ReviewRepository interface {
 	CreateReview(review *models.Review) error
 	GetReviewByID(id uuid.UUID) (*models.Review, error)
-	GetReviewsByPropertyID(propertyID uuid.UUID, offset, limit int) ([]*models.Review, error)
-	GetReviewsByUserID(userID uuid.UUID, offset, limit int) ([]*models.Review, error)
+	GetReviewsByPropertyID(propertyID uuid.UUID, page models.PageQuery) ([]*models.Review, error)
+	GetReviewsByUserID(userID uuid.UUID, page models.PageQuery) ([]*models.Review, error)
 	GetReviewByBookingID(bookingID uuid.UUID) (*models.Review, error)
 	UpdateReview(review *models.Review) error
 	DeleteReview(id uuid.UUID) error
-	ListReviews(offset, limit int) ([]*models.Review, error)
+	ListReviews(page models.PageQuery) ([]*models.Review, error)
 	GetAverageRating(propertyID uuid.UUID) (float64, error)
-}
\ No newline at end of file
+	GetRatingSummary(propertyID uuid.UUID) (*models.PropertyRatingResponse, error)
+}
+
+type TaxRuleRepository interface {
+	CreateTaxRule(rule *models.TaxRule) error
+	GetTaxRuleByID(id uuid.UUID) (*models.TaxRule, error)
+	UpdateTaxRule(rule *models.TaxRule) error
+	DeleteTaxRule(id uuid.UUID) error
+	ListTaxRules(page models.PageQuery) ([]*models.TaxRule, error)
+	GetMatchingTaxRules(country, state, city string) ([]*models.TaxRule, error)
+}
+
+type SavedSearchRepository interface {
+	CreateSavedSearch(search *models.SavedSearch) error
+	GetSavedSearchByID(id uuid.UUID) (*models.SavedSearch, error)
+	GetSavedSearchesByUserID(userID uuid.UUID, page models.PageQuery) ([]*models.SavedSearch, error)
+	UpdateSavedSearch(search *models.SavedSearch) error
+	DeleteSavedSearch(id uuid.UUID) error
+	GetSavedSearchesWithAlerts(afterID uuid.UUID, limit int) ([]*models.SavedSearch, error)
+	MarkSavedSearchRun(id uuid.UUID, runAt time.Time) error
+	WithMatcherLock(fn func() error) (bool, error)
+}
+
+type WishlistRepository interface {
+	CreateWishlist(wishlist *models.Wishlist) error
+	GetWishlistByID(id uuid.UUID) (*models.Wishlist, error)
+	GetWishlistByShareToken(token string) (*models.Wishlist, error)
+	GetWishlistsForUser(userID uuid.UUID, page models.PageQuery) ([]*models.Wishlist, error)
+	UpdateWishlist(wishlist *models.Wishlist) error
+	DeleteWishlist(id uuid.UUID) error
+	AddItem(item *models.WishlistItem) error
+	GetItemByID(id uuid.UUID) (*models.WishlistItem, error)
+	UpdateItem(item *models.WishlistItem) error
+	DeleteItem(id uuid.UUID) error
+	AddCollaborator(collaborator *models.WishlistCollaborator) error
+	RemoveCollaborator(wishlistID, userID uuid.UUID) error
+	GetFavoritedPropertyIDs(userID uuid.UUID, propertyIDs []uuid.UUID) ([]uuid.UUID, error)
+}
+
+type AmenityRepository interface {
+	CreateAmenity(amenity *models.Amenity) error
+	GetAmenityByID(id string) (*models.Amenity, error)
+	UpdateAmenity(amenity *models.Amenity) error
+	DeleteAmenity(id string) error
+	ListAmenities() ([]*models.Amenity, error)
+	CountPropertiesWithAmenity(id string) (int64, error)
+	RemapPropertyAmenities() (int64, error)
+	GetUnmappedAmenities() ([]*models.UnmappedAmenity, error)
+}
+
+type PropertyImageRepository interface {
+	AddImage(image *models.PropertyImage, maxImages int, revision *models.PropertyRevision) (bool, error)
+	GetImageByID(id uuid.UUID) (*models.PropertyImage, error)
+	GetImagesByPropertyID(propertyID uuid.UUID, includePending bool) ([]*models.PropertyImage, error)
+	UpdateImage(image *models.PropertyImage) error
+	SetCover(propertyID, imageID uuid.UUID, revision *models.PropertyRevision) error
+	ReorderImages(propertyID uuid.UUID, imageIDs []uuid.UUID, revision *models.PropertyRevision) error
+	DeleteImage(image *models.PropertyImage, revision *models.PropertyRevision) error
+	ReplaceImages(propertyID uuid.UUID, urls []string, revision *models.PropertyRevision) ([]*models.PropertyImage, error)
+}
+
+type ModerationRepository interface {
+	GetPendingProperties(req *models.ModerationQueueRequest) ([]*models.Property, int64, error)
+	CountRejections(propertyIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	RecordDecision(property *models.Property, decision *models.PropertyModerationDecision, revision *models.PropertyRevision) (bool, []*models.PropertyImage, error)
+	GetDecisionsByPropertyID(propertyID uuid.UUID) ([]*models.PropertyModerationDecision, error)
+}
+
+type PropertyRevisionRepository interface {
+	GetRevisionByID(id uuid.UUID) (*models.PropertyRevision, error)
+	GetRevisionsByPropertyID(propertyID uuid.UUID, page models.PageQuery) ([]*models.PropertyRevision, error)
+	GetLatestRevision(propertyID uuid.UUID) (*models.PropertyRevision, error)
+}
+
+type PropertyTranslationRepository interface {
+	UpsertTranslation(translation *models.PropertyTranslation) error
+	GetTranslationsByPropertyID(propertyID uuid.UUID) ([]*models.PropertyTranslation, error)
+	GetTranslations(propertyIDs []uuid.UUID, languages []string) ([]*models.PropertyTranslation, error)
+	DeleteTranslation(propertyID uuid.UUID, locale string) error
+}
+
+type CoHostRepository interface {
+	GetCoHost(propertyID, userID uuid.UUID) (*models.PropertyCoHost, error)
+	GetCoHostsByPropertyID(propertyID uuid.UUID) ([]*models.PropertyCoHost, error)
+	UpdateCoHostPermissions(propertyID, userID uuid.UUID, permissions []string) error
+	RemoveCoHost(propertyID, userID uuid.UUID) error
+	CreateInvitation(invitation *models.CoHostInvitation) error
+	GetInvitationByTokenHash(tokenHash string) (*models.CoHostInvitation, error)
+	GetPendingInvitations(propertyID uuid.UUID) ([]*models.CoHostInvitation, error)
+	DeleteInvitation(propertyID, invitationID uuid.UUID) error
+	AcceptInvitation(invitation *models.CoHostInvitation, cohost *models.PropertyCoHost) error
+}
+
+type HostAnalyticsRepository interface {
+	GetManagedPropertyIDs(userID uuid.UUID, permission models.CoHostPermission) ([]uuid.UUID, error)
+	GetOccupancy(propertyIDs []uuid.UUID, start, end time.Time) (*models.Occupancy, error)
+	GetRevenue(propertyIDs []uuid.UUID, start, end time.Time) ([]*models.CurrencyRevenue, error)
+	GetBookingActivity(propertyIDs []uuid.UUID, start, end time.Time) (*models.BookingActivity, error)
+	GetRatingTrend(propertyIDs []uuid.UUID, start, end time.Time) ([]*models.RatingTrendPoint, error)
+}
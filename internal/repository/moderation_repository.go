@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(db *gorm.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+// returns a page of the new listings and the changes to approved listings
+// waiting for a moderator, longest waiting first
+func (r *moderationRepository) GetPendingProperties(req *models.ModerationQueueRequest) ([]*models.Property, int64, error) {
+	var properties []*models.Property
+	var total int64
+
+	query := r.db.Model(&models.Property{})
+	switch {
+	case req.Changes == nil:
+		query = query.Where("(status = ? OR pending_changes IS NOT NULL)", models.PropertyStatusPending)
+	case *req.Changes:
+		query = query.Where("pending_changes IS NOT NULL")
+	default:
+		query = query.Where("status = ?", models.PropertyStatusPending)
+	}
+
+	if req.Query != "" {
+		query = query.Where("title ILIKE ?", "%"+likeEscaper.Replace(req.Query)+"%")
+	}
+	if req.City != "" {
+		query = query.Where("LOWER(city) LIKE LOWER(?)", "%"+likeEscaper.Replace(req.City)+"%")
+	}
+	if req.Country != "" {
+		query = query.Where("LOWER(country) LIKE LOWER(?)", "%"+likeEscaper.Replace(req.Country)+"%")
+	}
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.HostID != nil {
+		query = query.Where("host_id = ?", *req.HostID)
+	}
+	if req.Resubmitted != nil {
+		rejectedBefore := `EXISTS (
+			SELECT 1 FROM property_moderation_decisions
+			WHERE property_id = properties.id AND decision = 'rejected'
+		)`
+		if *req.Resubmitted {
+			query = query.Where(rejectedBefore)
+		} else {
+			query = query.Where("NOT " + rejectedBefore)
+		}
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("Host").
+		Order("COALESCE((pending_changes->>'requested_at')::timestamptz, submitted_at, created_at) ASC, id ASC").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&properties).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return properties, total, nil
+}
+
+// returns how often each of the properties was rejected, leaving out the
+// ones that never were
+func (r *moderationRepository) CountRejections(propertyIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(propertyIDs))
+	if len(propertyIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		PropertyID uuid.UUID
+		Count      int
+	}
+	err := r.db.Model(&models.PropertyModerationDecision{}).
+		Select("property_id, COUNT(*) AS count").
+		Where("property_id IN ? AND decision = ?", propertyIDs, models.ModerationDecisionRejected).
+		Group("property_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.PropertyID] = row.Count
+	}
+	return counts, nil
+}
+
+// saves the property as decided, with any approved changes applied, together
+// with the decision that led to it. decision.Changes are the changes the
+// moderator reviewed, nil for a new listing; their photos are shown when
+// approved and deleted when rejected, and the deleted images are returned.
+// Nothing is saved and false is returned when the listing is no longer
+// waiting for what was reviewed, such as after the host staged more changes.
+func (r *moderationRepository) RecordDecision(property *models.Property, decision *models.PropertyModerationDecision, revision *models.PropertyRevision) (bool, []*models.PropertyImage, error) {
+	recorded := false
+	var removed []*models.PropertyImage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Property
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status", "pending_changes").Where("id = ?", property.ID).First(&current).Error
+		if err != nil {
+			return err
+		}
+
+		reviewed := decision.Changes
+		approved := decision.Decision == models.ModerationDecisionApproved
+		columns := []string{"pending_changes", "updated_at"}
+		if reviewed == nil {
+			if current.Status != models.PropertyStatusPending || current.PendingChanges != nil {
+				return nil
+			}
+			columns = append(columns, "status", "approved_at")
+		} else {
+			if current.PendingChanges == nil || current.PendingChanges.Version != reviewed.Version {
+				return nil
+			}
+			if approved {
+				columns = append(columns, "type", "address", "city", "state", "country", "zip_code", "latitude", "longitude")
+			}
+
+			// images live in their own table and are applied first
+			if approved && reviewed.Images != nil {
+				if removed, err = replaceImages(tx, property.ID, reviewed.Images); err != nil {
+					return err
+				}
+			}
+			if approved && reviewed.NewImages {
+				if err := approveReviewedImages(tx, property.ID, reviewed); err != nil {
+					return err
+				}
+			}
+			if !approved && reviewed.NewImages {
+				if removed, err = deleteReviewedImages(tx, property.ID, reviewed); err != nil {
+					return err
+				}
+			}
+			if err := syncPropertyImages(tx, property.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(property).Select(columns).Updates(property).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(decision).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return recordRevision(tx, property.ID, revision)
+	})
+	return recorded, removed, err
+}
+
+// returns the decisions on a property, newest first
+func (r *moderationRepository) GetDecisionsByPropertyID(propertyID uuid.UUID) ([]*models.PropertyModerationDecision, error) {
+	var decisions []*models.PropertyModerationDecision
+	err := r.db.Preload("Reviewer").
+		Where("property_id = ?", propertyID).
+		Order("created_at DESC").
+		Find(&decisions).Error
+	return decisions, err
+}
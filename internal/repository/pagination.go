@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// paginate orders rows of table newest first and applies the page. It fetches
+// one row more than the limit so callers can tell whether another page follows.
+func paginate(db *gorm.DB, table string, page models.PageQuery) *gorm.DB {
+	db = db.Order(table + ".created_at DESC").Order(table + ".id DESC")
+
+	if page.After != nil {
+		db = db.Where(fmt.Sprintf("(%s.created_at, %s.id) < (?, ?)", table, table), page.After.CreatedAt, page.After.ID)
+	} else {
+		db = db.Offset(page.Offset)
+	}
+
+	return db.Limit(page.Limit + 1)
+}
@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type propertyImageRepository struct {
+	db *gorm.DB
+}
+
+func NewPropertyImageRepository(db *gorm.DB) PropertyImageRepository {
+	return &propertyImageRepository{db: db}
+}
+
+// copies the URLs of the images that are shown to properties.images, cover
+// first, then by position
+func syncPropertyImages(tx *gorm.DB, propertyID uuid.UUID) error {
+	return tx.Exec(`
+		UPDATE properties SET images = COALESCE((
+			SELECT array_agg(url ORDER BY is_cover DESC, position, created_at)
+			FROM property_images
+			WHERE property_id = ? AND NOT pending_review
+		), '{}')
+		WHERE id = ?
+	`, propertyID, propertyID).Error
+}
+
+// locks the property row so concurrent changes to its images are serialized
+func lockProperty(tx *gorm.DB, propertyID uuid.UUID) error {
+	var property models.Property
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ?", propertyID).First(&property).Error
+}
+
+// adds the image after the existing ones, unless the property already has
+// maxImages, and reports whether it did. The first shown image of a property
+// becomes its cover. An image waiting for review is added to the pending
+// changes of the property along with it.
+func (r *propertyImageRepository) AddImage(image *models.PropertyImage, maxImages int, revision *models.PropertyRevision) (bool, error) {
+	added := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockProperty(tx, image.PropertyID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.PropertyImage{}).Where("property_id = ?", image.PropertyID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(maxImages) {
+			return nil
+		}
+
+		var next struct {
+			Position int
+			HasCover bool
+		}
+		err := tx.Raw(`
+			SELECT COALESCE(MAX(position) + 1, 0) AS position, COALESCE(BOOL_OR(is_cover), false) AS has_cover
+			FROM property_images WHERE property_id = ?
+		`, image.PropertyID).Scan(&next).Error
+		if err != nil {
+			return err
+		}
+
+		image.Position = next.Position
+		image.IsCover = !next.HasCover && !image.PendingReview
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+
+		if image.PendingReview {
+			changes := &models.PropertyChanges{
+				NewImages:   true,
+				NewImageIDs: []uuid.UUID{image.ID},
+				RequestedAt: &image.CreatedAt,
+			}
+			if _, err := mergePendingChanges(tx, image.PropertyID, changes); err != nil {
+				return err
+			}
+		}
+
+		if err := syncPropertyImages(tx, image.PropertyID); err != nil {
+			return err
+		}
+		added = true
+		return recordRevision(tx, image.PropertyID, revision)
+	})
+	return added, err
+}
+
+func (r *propertyImageRepository) GetImageByID(id uuid.UUID) (*models.PropertyImage, error) {
+	var image models.PropertyImage
+	err := r.db.Where("id = ?", id).First(&image).Error
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// returns the images of a property in display order, with the ones waiting
+// for review when includePending is set
+func (r *propertyImageRepository) GetImagesByPropertyID(propertyID uuid.UUID, includePending bool) ([]*models.PropertyImage, error) {
+	var images []*models.PropertyImage
+	query := r.db.Where("property_id = ?", propertyID)
+	if !includePending {
+		query = query.Where("NOT pending_review")
+	}
+	err := query.Order("is_cover DESC, position, created_at").Find(&images).Error
+	return images, err
+}
+
+func (r *propertyImageRepository) UpdateImage(image *models.PropertyImage) error {
+	return r.db.Save(image).Error
+}
+
+func (r *propertyImageRepository) SetCover(propertyID, imageID uuid.UUID, revision *models.PropertyRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockProperty(tx, propertyID); err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.PropertyImage{}).
+			Where("property_id = ? AND is_cover", propertyID).
+			Update("is_cover", false).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.PropertyImage{}).
+			Where("id = ? AND property_id = ?", imageID, propertyID).
+			Update("is_cover", true).Error
+		if err != nil {
+			return err
+		}
+
+		if err := syncPropertyImages(tx, propertyID); err != nil {
+			return err
+		}
+		return recordRevision(tx, propertyID, revision)
+	})
+}
+
+// gives the images the positions of their ids in imageIDs
+func (r *propertyImageRepository) ReorderImages(propertyID uuid.UUID, imageIDs []uuid.UUID, revision *models.PropertyRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockProperty(tx, propertyID); err != nil {
+			return err
+		}
+
+		for position, id := range imageIDs {
+			err := tx.Model(&models.PropertyImage{}).
+				Where("id = ? AND property_id = ?", id, propertyID).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := syncPropertyImages(tx, propertyID); err != nil {
+			return err
+		}
+		return recordRevision(tx, propertyID, revision)
+	})
+}
+
+// deletes the image. When it was the cover, the first remaining image takes
+// its place.
+func (r *propertyImageRepository) DeleteImage(image *models.PropertyImage, revision *models.PropertyRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockProperty(tx, image.PropertyID); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&models.PropertyImage{}, image.ID).Error; err != nil {
+			return err
+		}
+
+		if image.IsCover {
+			err := tx.Exec(`
+				UPDATE property_images SET is_cover = true
+				WHERE id = (
+					SELECT id FROM property_images WHERE property_id = ? AND NOT pending_review
+					ORDER BY position, created_at LIMIT 1
+				)
+			`, image.PropertyID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := syncPropertyImages(tx, image.PropertyID); err != nil {
+			return err
+		}
+		return recordRevision(tx, image.PropertyID, revision)
+	})
+}
+
+// makes the images match urls, see ReplaceImages, and returns the deleted
+// ones. The caller holds the lock of the property and syncs its images.
+func replaceImages(tx *gorm.DB, propertyID uuid.UUID, urls []string) ([]*models.PropertyImage, error) {
+	var removed []*models.PropertyImage
+
+	// clear the cover first, only one image may hold it at a time
+	err := tx.Model(&models.PropertyImage{}).
+		Where("property_id = ? AND is_cover", propertyID).
+		Update("is_cover", false).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []*models.PropertyImage
+	if err := tx.Where("property_id = ?", propertyID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]*models.PropertyImage, len(existing))
+	for _, image := range existing {
+		byURL[image.URL] = image
+	}
+
+	for position, url := range urls {
+		image, ok := byURL[url]
+		if !ok {
+			image = &models.PropertyImage{PropertyID: propertyID, URL: url}
+		}
+		delete(byURL, url)
+
+		image.Position = position
+		image.IsCover = position == 0
+		image.PendingReview = false
+		if err := tx.Save(image).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, image := range byURL {
+		if image.PendingReview {
+			continue
+		}
+		if err := tx.Delete(&models.PropertyImage{}, image.ID).Error; err != nil {
+			return nil, err
+		}
+		removed = append(removed, image)
+	}
+
+	return removed, nil
+}
+
+// makes the images of the property match urls, in that order with the first
+// as cover. Images whose URL is listed are kept and shown, new URLs are added
+// as images that were not uploaded, and the rest are deleted and returned.
+// Images waiting for review that are not listed are left alone.
+func (r *propertyImageRepository) ReplaceImages(propertyID uuid.UUID, urls []string, revision *models.PropertyRevision) ([]*models.PropertyImage, error) {
+	var removed []*models.PropertyImage
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockProperty(tx, propertyID); err != nil {
+			return err
+		}
+
+		var err error
+		removed, err = replaceImages(tx, propertyID, urls)
+		if err != nil {
+			return err
+		}
+
+		if err := syncPropertyImages(tx, propertyID); err != nil {
+			return err
+		}
+		return recordRevision(tx, propertyID, revision)
+	})
+	return removed, err
+}
+
+// narrows a query on images to the photos waiting for review that the changes
+// asked to show
+func reviewedImages(query *gorm.DB, propertyID uuid.UUID, changes *models.PropertyChanges) *gorm.DB {
+	query = query.Where("property_id = ? AND pending_review", propertyID)
+	if len(changes.NewImageIDs) > 0 {
+		query = query.Where("id IN ?", changes.NewImageIDs)
+	}
+	return query
+}
+
+// shows the photos of the changes that were waiting for review. When the
+// property has no cover yet, the first of them becomes it. The caller holds
+// the lock of the property and syncs its images.
+func approveReviewedImages(tx *gorm.DB, propertyID uuid.UUID, changes *models.PropertyChanges) error {
+	err := reviewedImages(tx.Model(&models.PropertyImage{}), propertyID, changes).
+		Update("pending_review", false).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE property_images SET is_cover = true
+		WHERE id = (
+			SELECT id FROM property_images WHERE property_id = ? AND NOT pending_review
+			ORDER BY position, created_at LIMIT 1
+		)
+		AND NOT EXISTS (SELECT 1 FROM property_images WHERE property_id = ? AND is_cover)
+	`, propertyID, propertyID).Error
+}
+
+// deletes the photos of the changes that were waiting for review and returns
+// them
+func deleteReviewedImages(tx *gorm.DB, propertyID uuid.UUID, changes *models.PropertyChanges) ([]*models.PropertyImage, error) {
+	var images []*models.PropertyImage
+	err := reviewedImages(tx.Clauses(clause.Returning{}), propertyID, changes).Delete(&images).Error
+	return images, err
+}
@@ -2,13 +2,38 @@ package repository
 
 import (
 	"airbnb-clone/internal/models"
+	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// earth position of a property, matching the expression of idx_properties_earth
+const earthPointSQL = "ll_to_earth(latitude::float8, longitude::float8)"
+
+// number of amenities returned in search facets
+const maxAmenityFacets = 50
+
+// properties with a translation matching a query in a language, see
+// migrateTranslationSearchVector. Takes the locales, the locale of the query
+// and the query.
+const translationMatchSQL = "SELECT property_id FROM property_translations WHERE locale IN ? AND search_vector @@ websearch_to_tsquery(locale_search_config(?), ?)"
+
+// returns the locales whose translations a search in locale also matches,
+// none when it is the language of the original text
+func translatedLocales(locale string) []string {
+	if locale == "" || locale == models.DefaultLocale {
+		return nil
+	}
+	return models.LocaleFallbacks(locale)
+}
+
 type propertyRepository struct {
 	db *gorm.DB
 }
@@ -17,8 +42,32 @@ func NewPropertyRepository(db *gorm.DB) PropertyRepository {
 	return &propertyRepository{db: db}
 }
 
-func (r *propertyRepository) Create(property *models.Property) error {
-	return r.db.Create(property).Error
+// creates the property together with its images, one for each URL in
+// property.Images with the first as cover, so a listing is never left behind
+// without them, and its first revision
+func (r *propertyRepository) Create(property *models.Property, revision *models.PropertyRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(property).Error; err != nil {
+			return err
+		}
+
+		for position, url := range property.Images {
+			image := &models.PropertyImage{
+				PropertyID: property.ID,
+				URL:        url,
+				Position:   position,
+				IsCover:    position == 0,
+			}
+			if err := tx.Create(image).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := syncPropertyImages(tx, property.ID); err != nil {
+			return err
+		}
+		return recordRevision(tx, property.ID, revision)
+	})
 }
 
 func (r *propertyRepository) GetPropertyByID(id uuid.UUID) (*models.Property, error) {
@@ -30,75 +79,183 @@ func (r *propertyRepository) GetPropertyByID(id uuid.UUID) (*models.Property, er
 	return &property, nil
 }
 
-func (r *propertyRepository) UpdateProperty(property *models.Property) error {
-	return r.db.Save(property).Error
+// saves the property if it is still as it was loaded, going by updated_at,
+// and reports whether it was. A listing a moderator decided on meanwhile is
+// left alone rather than set back to what the host loaded. Images are left
+// out, they are written from property_images, see syncPropertyImages, and so
+// are the fields hosts do not edit. Pending changes are only ever merged
+// into, see mergePendingChanges; pending are those this update adds, if any.
+func (r *propertyRepository) UpdateProperty(property *models.Property, pending *models.PropertyChanges, revision *models.PropertyRevision) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(property).Where("updated_at = ?", property.UpdatedAt).
+			Select("*").
+			Omit(clause.Associations, "ID", "HostID", "Images", "PendingChanges", "UnmappedAmenities", "ApprovedAt", "CreatedAt", "DeletedAt").
+			Updates(property)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		updated = true
+
+		if pending != nil {
+			merged, err := mergePendingChanges(tx, property.ID, pending)
+			if err != nil {
+				return err
+			}
+			property.PendingChanges = merged
+		}
+
+		return recordRevision(tx, property.ID, revision)
+	})
+	return updated, err
 }
 
 func (r *propertyRepository) DeleteProperty(id uuid.UUID) error {
 	return r.db.Delete(&models.Property{}, id).Error
 }
 
-func (r *propertyRepository) ListProperties(offset, limit int) ([]*models.Property, error) {
+func (r *propertyRepository) ListProperties(page models.PageQuery) ([]*models.Property, error) {
 	var properties []*models.Property
-	err := r.db.Preload("Host").Where("status = ?", "active").Offset(offset).Limit(limit).Find(&properties).Error
+	query := r.db.Preload("Host").Where("status = ?", "active")
+	err := paginate(query, "properties", page).Find(&properties).Error
 	return properties, err
 }
 
-func (r *propertyRepository) SearchProperties(req *models.PropertySearchRequest) ([]*models.Property, int64, error) {
-	var properties []*models.Property
-	var total int64
+// searchCondition is one filter of a property search. Conditions tagged with
+// a facet are left out when counting that facet, so a facet shows the counts
+// for every choice rather than only the selected one.
+type searchCondition struct {
+	facet string
+	sql   string
+	args  []interface{}
+}
 
-	// Build the base query
-	query := r.db.Model(&models.Property{}).Preload("Host")
-	countQuery := r.db.Model(&models.Property{})
+type searchConditions []searchCondition
+
+// facets that ignore their own filter
+const (
+	facetType      = "type"
+	facetAmenities = "amenities"
+	facetBedrooms  = "bedrooms"
+	facetPrice     = "price"
+)
+
+func (c searchConditions) add(facet, expr string, args ...interface{}) searchConditions {
+	return append(c, searchCondition{facet: facet, sql: expr, args: args})
+}
 
-	// Apply filters
-	whereClause := "status = 'active'"
+// where joins all conditions except those of the given facet
+func (c searchConditions) where(exceptFacet string) (string, []interface{}) {
+	clauses := make([]string, 0, len(c))
 	args := []interface{}{}
+	for _, condition := range c {
+		if exceptFacet != "" && condition.facet == exceptFacet {
+			continue
+		}
+		clauses = append(clauses, condition.sql)
+		args = append(args, condition.args...)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+func buildSearchConditions(req *models.PropertySearchRequest) searchConditions {
+	conditions := searchConditions{}.add("", "status = 'active'")
+
+	// Full-text search over title and description, see migrateSearchVector,
+	// and their translations to the language of the request
+	if req.Query != "" {
+		if locales := translatedLocales(req.Locale); len(locales) > 0 {
+			conditions = conditions.add("",
+				"(search_vector @@ websearch_to_tsquery('english', ?) OR properties.id IN ("+translationMatchSQL+"))",
+				req.Query, locales, req.Locale, req.Query)
+		} else {
+			conditions = conditions.add("", "search_vector @@ websearch_to_tsquery('english', ?)", req.Query)
+		}
+	}
 
 	if req.City != "" {
-		whereClause += " AND LOWER(city) LIKE LOWER(?)"
-		args = append(args, "%"+req.City+"%")
+		conditions = conditions.add("", "LOWER(city) LIKE LOWER(?)", "%"+req.City+"%")
 	}
 
 	if req.State != "" {
-		whereClause += " AND LOWER(state) LIKE LOWER(?)"
-		args = append(args, "%"+req.State+"%")
+		conditions = conditions.add("", "LOWER(state) LIKE LOWER(?)", "%"+req.State+"%")
 	}
 
 	if req.Country != "" {
-		whereClause += " AND LOWER(country) LIKE LOWER(?)"
-		args = append(args, "%"+req.Country+"%")
+		conditions = conditions.add("", "LOWER(country) LIKE LOWER(?)", "%"+req.Country+"%")
 	}
 
 	if req.Guests > 0 {
-		whereClause += " AND max_guests >= ?"
-		args = append(args, req.Guests)
+		conditions = conditions.add("", "max_guests >= ?", req.Guests)
+	}
+
+	if req.MinBedrooms > 0 {
+		conditions = conditions.add(facetBedrooms, "bedrooms >= ?", req.MinBedrooms)
+	}
+
+	if req.MinBeds > 0 {
+		conditions = conditions.add("", "total_beds >= ?", req.MinBeds)
 	}
 
 	if req.MinPrice > 0 {
-		whereClause += " AND price_per_night >= ?"
-		args = append(args, req.MinPrice)
+		conditions = conditions.add(facetPrice, "price_per_night >= ?", req.MinPrice)
 	}
 
 	if req.MaxPrice > 0 {
-		whereClause += " AND price_per_night <= ?"
-		args = append(args, req.MaxPrice)
+		conditions = conditions.add(facetPrice, "price_per_night <= ?", req.MaxPrice)
 	}
 
 	if req.Type != "" {
-		whereClause += " AND type = ?"
-		args = append(args, req.Type)
+		conditions = conditions.add(facetType, "type = ?", req.Type)
 	}
 
-	// Handle amenities search using raw SQL for array operations
+	// Properties must have every requested amenity
 	if len(req.Amenities) > 0 {
-		amenitiesPlaceholders := make([]string, len(req.Amenities))
-		for i, amenity := range req.Amenities {
-			amenitiesPlaceholders[i] = "?"
-			args = append(args, amenity)
+		conditions = conditions.add(facetAmenities, "amenities @> ?", pq.StringArray(req.Amenities))
+	}
+
+	// House rules only match listings whose host stated them, so asking for
+	// a smoke-free stay leaves out listings that never said
+	houseRules := []struct {
+		column string
+		value  *bool
+	}{
+		{"pets_allowed", req.PetsAllowed},
+		{"smoking_allowed", req.SmokingAllowed},
+		{"events_allowed", req.EventsAllowed},
+		{"children_allowed", req.ChildrenAllowed},
+		{"infants_allowed", req.InfantsAllowed},
+	}
+	for _, rule := range houseRules {
+		if rule.value != nil {
+			conditions = conditions.add("", rule.column+" = ?", *rule.value)
+		}
+	}
+
+	// Properties must have every requested accessibility feature
+	if len(req.Accessibility) > 0 {
+		conditions = conditions.add("", "accessibility_features @> ?", pq.StringArray(req.Accessibility))
+	}
+
+	// Geospatial filters use the earthdistance extension, see idx_properties_earth
+	if req.Lat != nil && req.Lng != nil && req.RadiusKm > 0 {
+		radiusMeters := req.RadiusKm * 1000
+		conditions = conditions.add("",
+			fmt.Sprintf("earth_box(ll_to_earth(?, ?), ?) @> %s AND earth_distance(ll_to_earth(?, ?), %s) <= ?", earthPointSQL, earthPointSQL),
+			*req.Lat, *req.Lng, radiusMeters, *req.Lat, *req.Lng, radiusMeters)
+	}
+
+	if req.BBox != nil {
+		conditions = conditions.add("", "latitude BETWEEN ? AND ?", req.BBox.MinLat, req.BBox.MaxLat)
+		if req.BBox.MinLng <= req.BBox.MaxLng {
+			conditions = conditions.add("", "longitude BETWEEN ? AND ?", req.BBox.MinLng, req.BBox.MaxLng)
+		} else {
+			// the box crosses the antimeridian
+			conditions = conditions.add("", "(longitude >= ? OR longitude <= ?)", req.BBox.MinLng, req.BBox.MaxLng)
 		}
-		whereClause += fmt.Sprintf(" AND amenities ?& ARRAY[%s]", strings.Join(amenitiesPlaceholders, ","))
 	}
 
 	// Apply availability filter if check-in and check-out dates are provided
@@ -111,12 +268,39 @@ func (r *propertyRepository) SearchProperties(req *models.PropertySearchRequest)
 				AND NOT (check_out <= ? OR check_in >= ?)
 			)
 		`
-		whereClause += " AND " + subQuery
-		args = append(args, req.CheckIn, req.CheckOut)
+		conditions = conditions.add("", subQuery, req.CheckIn, req.CheckOut)
 	}
 
+	// Listings updated since then, or whose dates were freed by a cancellation
+	if req.ChangedSince != nil {
+		subQuery := `
+			(properties.updated_at > ? OR EXISTS (
+				SELECT 1 FROM bookings
+				WHERE bookings.property_id = properties.id
+				AND bookings.status = 'cancelled'
+				AND bookings.updated_at > ?
+			))
+		`
+		conditions = conditions.add("", subQuery, *req.ChangedSince, *req.ChangedSince)
+	}
+
+	return conditions
+}
+
+func (r *propertyRepository) SearchProperties(req *models.PropertySearchRequest) ([]*models.Property, int64, error) {
+	var properties []*models.Property
+	var total int64
+
+	// Build the base query
+	query := r.db.Model(&models.Property{}).Preload("Host")
+	countQuery := r.db.Model(&models.Property{})
+
 	// Apply where clause to both queries
+	whereClause, args := buildSearchConditions(req).where("")
 	query = query.Where(whereClause, args...)
+	if req.Lat != nil && req.Lng != nil {
+		query = query.Select(fmt.Sprintf("properties.*, earth_distance(ll_to_earth(?, ?), %s) / 1000 AS distance_km", earthPointSQL), *req.Lat, *req.Lng)
+	}
 	countQuery = countQuery.Where(whereClause, args...)
 
 	// Get total count
@@ -134,7 +318,53 @@ func (r *propertyRepository) SearchProperties(req *models.PropertySearchRequest)
 	}
 	offset := (req.Page - 1) * req.Limit
 
-	// Execute the main query
+	// Apply sort order, ranking full-text matches by relevance unless asked otherwise
+	sort := req.Sort
+	if sort == "" {
+		sort = models.SearchSortNewest
+		if req.Query != "" {
+			sort = models.SearchSortRelevance
+		}
+	}
+
+	switch sort {
+	case models.SearchSortPriceAsc:
+		query = query.Order("price_per_night ASC")
+	case models.SearchSortPriceDesc:
+		query = query.Order("price_per_night DESC")
+	case models.SearchSortRating:
+		query = query.Order("average_rating DESC").Order("review_count DESC")
+	case models.SearchSortDistance:
+		if req.Lat != nil && req.Lng != nil {
+			query = query.Order(clause.Expr{
+				SQL:  fmt.Sprintf("earth_distance(ll_to_earth(?, ?), %s) ASC", earthPointSQL),
+				Vars: []interface{}{*req.Lat, *req.Lng},
+			})
+		}
+	case models.SearchSortRelevance:
+		if locales := translatedLocales(req.Locale); req.Query != "" && len(locales) > 0 {
+			// the better of the original and the translated text
+			query = query.Order(clause.Expr{
+				SQL: `GREATEST(ts_rank_cd(search_vector, websearch_to_tsquery('english', ?)), COALESCE((
+					SELECT MAX(ts_rank_cd(t.search_vector, websearch_to_tsquery(locale_search_config(?), ?)))
+					FROM property_translations t WHERE t.property_id = properties.id AND t.locale IN ?
+				), 0)) DESC`,
+				Vars: []interface{}{req.Query, req.Locale, req.Query, locales},
+			})
+		} else if req.Query != "" {
+			query = query.Order(clause.Expr{
+				SQL:  "ts_rank_cd(search_vector, websearch_to_tsquery('english', ?)) DESC",
+				Vars: []interface{}{req.Query},
+			})
+		}
+	}
+
+	// Tie-break on creation order so pages are stable
+	query = query.Order("created_at DESC").Order("id DESC")
+
+	// Execute the main query. Search stays on offset paging: the rank,
+	// distance and price orders are computed per request, so there is no
+	// stable key to resume from, and the page count comes with the total.
 	err = query.Offset(offset).Limit(req.Limit).Find(&properties).Error
 	if err != nil {
 		return nil, 0, err
@@ -143,26 +373,178 @@ func (r *propertyRepository) SearchProperties(req *models.PropertySearchRequest)
 	return properties, total, nil
 }
 
-func (r *propertyRepository) GetPropertiesByHostID(hostID uuid.UUID, offset, limit int) ([]*models.Property, error) {
+// counts search results per type, amenity, bedroom count and price bucket
+func (r *propertyRepository) SearchFacets(req *models.PropertySearchRequest) (*models.SearchFacets, error) {
+	conditions := buildSearchConditions(req)
+	facets := &models.SearchFacets{}
+
+	whereClause, args := conditions.where(facetType)
+	err := r.db.Model(&models.Property{}).
+		Select("type AS value, COUNT(*) AS count").
+		Where(whereClause, args...).
+		Group("type").Order("count DESC, value").
+		Scan(&facets.Types).Error
+	if err != nil {
+		return nil, err
+	}
+
+	whereClause, args = conditions.where(facetAmenities)
+	err = r.db.Model(&models.Property{}).
+		Select("amenity AS value, COUNT(*) AS count").
+		Joins("CROSS JOIN LATERAL unnest(properties.amenities) AS amenity").
+		Where(whereClause, args...).
+		Group("amenity").Order("count DESC, value").
+		Limit(maxAmenityFacets).
+		Scan(&facets.Amenities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	whereClause, args = conditions.where(facetBedrooms)
+	err = r.db.Model(&models.Property{}).
+		Select("bedrooms::text AS value, COUNT(*) AS count").
+		Where(whereClause, args...).
+		Group("bedrooms").Order("bedrooms").
+		Scan(&facets.Bedrooms).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// width_bucket returns 0 below the first bound and len(bounds) above the last
+	var bucketCounts []struct {
+		Bucket int
+		Count  int64
+	}
+	bounds := make([]string, len(models.SearchPriceBucketBounds))
+	for i, bound := range models.SearchPriceBucketBounds {
+		bounds[i] = strconv.FormatFloat(bound, 'f', -1, 64)
+	}
+	whereClause, args = conditions.where(facetPrice)
+	err = r.db.Model(&models.Property{}).
+		Select(fmt.Sprintf("width_bucket(price_per_night::numeric, ARRAY[%s]::numeric[]) AS bucket, COUNT(*) AS count", strings.Join(bounds, ","))).
+		Where(whereClause, args...).
+		Group("bucket").
+		Scan(&bucketCounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	facets.PriceBuckets = models.NewPriceBuckets()
+	for _, row := range bucketCounts {
+		if row.Bucket >= 0 && row.Bucket < len(facets.PriceBuckets) {
+			facets.PriceBuckets[row.Bucket].Count = row.Count
+		}
+	}
+
+	return facets, nil
+}
+
+// returns the locations of active listings where the city, state or country
+// starts with prefix, most listings first. The prefix matches are served by
+// the trigram indexes on the lowercased columns.
+func (r *propertyRepository) SuggestDestinations(prefix string, limit int) ([]*models.DestinationSuggestion, error) {
+	pattern := likeEscaper.Replace(strings.ToLower(prefix)) + "%"
+
+	var suggestions []*models.DestinationSuggestion
+	err := r.db.Model(&models.Property{}).
+		Select("city, state, country, COUNT(*) AS listing_count").
+		Where("status = ?", models.PropertyStatusActive).
+		Where("LOWER(city) LIKE ? OR LOWER(state) LIKE ? OR LOWER(country) LIKE ?", pattern, pattern, pattern).
+		Group("city, state, country").
+		Order("listing_count DESC, city, state, country").
+		Limit(limit).
+		Scan(&suggestions).Error
+	return suggestions, err
+}
+
+// returns up to limit active listings within radiusKm of the property,
+// nearest first, as candidates for similar-listing recommendations. When
+// dates are given, listings booked for any of those nights are left out.
+func (r *propertyRepository) GetSimilarCandidates(property *models.Property, radiusKm float64, checkIn, checkOut time.Time, limit int) ([]*models.Property, error) {
+	req := &models.PropertySearchRequest{
+		Lat:      &property.Latitude,
+		Lng:      &property.Longitude,
+		RadiusKm: radiusKm,
+		CheckIn:  checkIn,
+		CheckOut: checkOut,
+	}
+	whereClause, args := buildSearchConditions(req).where("")
+
+	var properties []*models.Property
+	err := r.db.Model(&models.Property{}).
+		Select(fmt.Sprintf("properties.*, earth_distance(ll_to_earth(?, ?), %s) / 1000 AS distance_km", earthPointSQL), property.Latitude, property.Longitude).
+		Where(whereClause, args...).
+		Where("properties.id <> ?", property.ID).
+		Order("distance_km ASC").
+		Limit(limit).
+		Find(&properties).Error
+	return properties, err
+}
+
+// returns the active listings among ids, in no particular order. Deleted
+// listings are skipped by the soft delete scope.
+func (r *propertyRepository) GetActivePropertiesByIDs(ids []uuid.UUID) ([]*models.Property, error) {
+	var properties []*models.Property
+	if len(ids) == 0 {
+		return properties, nil
+	}
+
+	err := r.db.Where("id IN ? AND status = ?", ids, models.PropertyStatusActive).Find(&properties).Error
+	return properties, err
+}
+
+// escapes the LIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// returns the properties the user hosts or co-hosts
+func (r *propertyRepository) GetPropertiesByHostID(hostID uuid.UUID, page models.PageQuery) ([]*models.Property, error) {
 	var properties []*models.Property
-	err := r.db.Where("host_id = ?", hostID).Offset(offset).Limit(limit).Find(&properties).Error
+	query := r.db.Where("host_id = ? OR id IN (SELECT property_id FROM property_cohosts WHERE user_id = ?)", hostID, hostID)
+	err := paginate(query, "properties", page).Find(&properties).Error
 	return properties, err
 }
 
+// adds changes on top of the ones already waiting for a moderator. The merge
+// happens under the row lock, so changes staged meanwhile are kept.
+func mergePendingChanges(tx *gorm.DB, propertyID uuid.UUID, changes *models.PropertyChanges) (*models.PropertyChanges, error) {
+	var property models.Property
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "pending_changes").Where("id = ?", propertyID).First(&property).Error
+	if err != nil {
+		return nil, err
+	}
+
+	merged := property.PendingChanges.Merge(changes)
+	err = tx.Model(&models.Property{}).Where("id = ?", propertyID).Update("pending_changes", merged).Error
+	return merged, err
+}
+
 func (r *propertyRepository) CheckAvailability(propertyID uuid.UUID, checkIn, checkOut string) (bool, error) {
 	var count int64
-	
+
 	query := `
 		SELECT COUNT(*) FROM bookings 
 		WHERE property_id = ? 
 		AND status IN ('confirmed', 'pending')
 		AND NOT (check_out <= ? OR check_in >= ?)
 	`
-	
+
 	err := r.db.Raw(query, propertyID, checkIn, checkOut).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
-	
+
 	return count == 0, nil
 }
+
+// recomputes the denormalized average rating and review count of a property
+func (r *propertyRepository) UpdateRatingSummary(propertyID uuid.UUID) error {
+	query := `
+		UPDATE properties SET
+			average_rating = COALESCE((SELECT AVG(rating) FROM reviews WHERE property_id = @id AND deleted_at IS NULL), 0),
+			review_count = (SELECT COUNT(*) FROM reviews WHERE property_id = @id AND deleted_at IS NULL)
+		WHERE id = @id
+	`
+
+	return r.db.Exec(query, sql.Named("id", propertyID)).Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type propertyRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewPropertyRevisionRepository(db *gorm.DB) PropertyRevisionRepository {
+	return &propertyRevisionRepository{db: db}
+}
+
+// recordRevision saves the property as the transaction wrote it, its synced
+// images included, as the next revision, listing the fields that changed
+// since the last one. Nothing is saved when revision is nil or the snapshot
+// matches the last revision. Callers hold the row lock of the property, which
+// numbers are taken under.
+func recordRevision(tx *gorm.DB, propertyID uuid.UUID, revision *models.PropertyRevision) error {
+	if revision == nil {
+		return nil
+	}
+
+	var property models.Property
+	if err := tx.Where("id = ?", propertyID).First(&property).Error; err != nil {
+		return err
+	}
+	revision.PropertyID = propertyID
+	revision.Snapshot = property.Snapshot()
+
+	var latest models.PropertyRevision
+	err := tx.Where("property_id = ?", propertyID).Order("number DESC").First(&latest).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		revision.Number = 1
+	case err != nil:
+		return err
+	default:
+		changed := revision.Snapshot.ChangedFields(latest.Snapshot)
+		if len(changed) == 0 {
+			return nil
+		}
+		revision.Number = latest.Number + 1
+		revision.ChangedFields = changed
+	}
+
+	return tx.Create(revision).Error
+}
+
+func (r *propertyRevisionRepository) GetRevisionByID(id uuid.UUID) (*models.PropertyRevision, error) {
+	var revision models.PropertyRevision
+	err := r.db.Where("id = ?", id).First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *propertyRevisionRepository) GetRevisionsByPropertyID(propertyID uuid.UUID, page models.PageQuery) ([]*models.PropertyRevision, error) {
+	var revisions []*models.PropertyRevision
+	query := r.db.Where("property_id = ?", propertyID)
+	err := paginate(query, "property_revisions", page).Find(&revisions).Error
+	return revisions, err
+}
+
+// returns the revision that is live, gorm.ErrRecordNotFound when the property
+// has none yet
+func (r *propertyRevisionRepository) GetLatestRevision(propertyID uuid.UUID) (*models.PropertyRevision, error) {
+	var revision models.PropertyRevision
+	err := r.db.Where("property_id = ?", propertyID).Order("number DESC").First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
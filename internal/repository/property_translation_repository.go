@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type propertyTranslationRepository struct {
+	db *gorm.DB
+}
+
+func NewPropertyTranslationRepository(db *gorm.DB) PropertyTranslationRepository {
+	return &propertyTranslationRepository{db: db}
+}
+
+// saves the translation, replacing the one of the property in the same locale
+func (r *propertyTranslationRepository) UpsertTranslation(translation *models.PropertyTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "property_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "description", "rules", "updated_at"}),
+	}).Create(translation).Error
+}
+
+func (r *propertyTranslationRepository) GetTranslationsByPropertyID(propertyID uuid.UUID) ([]*models.PropertyTranslation, error) {
+	var translations []*models.PropertyTranslation
+	err := r.db.Where("property_id = ?", propertyID).Order("locale").Find(&translations).Error
+	return translations, err
+}
+
+// returns the translations of the properties in any of the languages, e.g.
+// "pt", whatever their region
+func (r *propertyTranslationRepository) GetTranslations(propertyIDs []uuid.UUID, languages []string) ([]*models.PropertyTranslation, error) {
+	var translations []*models.PropertyTranslation
+	if len(propertyIDs) == 0 || len(languages) == 0 {
+		return translations, nil
+	}
+	err := r.db.Where("property_id IN ? AND split_part(locale, '-', 1) IN ?", propertyIDs, languages).Find(&translations).Error
+	return translations, err
+}
+
+// deletes the translation, gorm.ErrRecordNotFound when there is none
+func (r *propertyTranslationRepository) DeleteTranslation(propertyID uuid.UUID, locale string) error {
+	result := r.db.Where("property_id = ? AND locale = ?", propertyID, locale).Delete(&models.PropertyTranslation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
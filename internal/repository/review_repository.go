@@ -27,21 +27,19 @@ func (r *reviewRepository) GetReviewByID(id uuid.UUID) (*models.Review, error) {
 	return &review, nil
 }
 
-func (r *reviewRepository) GetReviewsByPropertyID(propertyID uuid.UUID, offset, limit int) ([]*models.Review, error) {
+func (r *reviewRepository) GetReviewsByPropertyID(propertyID uuid.UUID, page models.PageQuery) ([]*models.Review, error) {
 	var reviews []*models.Review
-	err := r.db.Preload("Reviewer").
-		Where("property_id = ?", propertyID).
-		Order("created_at DESC").
-		Offset(offset).Limit(limit).Find(&reviews).Error
+	query := r.db.Preload("Reviewer").
+		Where("property_id = ?", propertyID)
+	err := paginate(query, "reviews", page).Find(&reviews).Error
 	return reviews, err
 }
 
-func (r *reviewRepository) GetReviewsByUserID(userID uuid.UUID, offset, limit int) ([]*models.Review, error) {
+func (r *reviewRepository) GetReviewsByUserID(userID uuid.UUID, page models.PageQuery) ([]*models.Review, error) {
 	var reviews []*models.Review
-	err := r.db.Preload("Property").
-		Where("reviewer_id = ?", userID).
-		Order("created_at DESC").
-		Offset(offset).Limit(limit).Find(&reviews).Error
+	query := r.db.Preload("Property").
+		Where("reviewer_id = ?", userID)
+	err := paginate(query, "reviews", page).Find(&reviews).Error
 	return reviews, err
 }
 
@@ -63,11 +61,10 @@ func (r *reviewRepository) DeleteReview(id uuid.UUID) error {
 	return r.db.Delete(&models.Review{}, id).Error
 }
 
-func (r *reviewRepository) ListReviews(offset, limit int) ([]*models.Review, error) {
+func (r *reviewRepository) ListReviews(page models.PageQuery) ([]*models.Review, error) {
 	var reviews []*models.Review
-	err := r.db.Preload("Property").Preload("Reviewer").
-		Order("created_at DESC").
-		Offset(offset).Limit(limit).Find(&reviews).Error
+	query := r.db.Preload("Property").Preload("Reviewer")
+	err := paginate(query, "reviews", page).Find(&reviews).Error
 	return reviews, err
 }
 
@@ -83,3 +80,16 @@ func (r *reviewRepository) GetAverageRating(propertyID uuid.UUID) (float64, erro
 	err := r.db.Raw(query, propertyID).Scan(&avgRating).Error
 	return avgRating, err
 }
+
+func (r *reviewRepository) GetRatingSummary(propertyID uuid.UUID) (*models.PropertyRatingResponse, error) {
+	summary := &models.PropertyRatingResponse{PropertyID: propertyID}
+
+	query := `
+		SELECT COALESCE(AVG(rating), 0) AS average_rating, COUNT(*) AS review_count
+		FROM reviews
+		WHERE property_id = ? AND deleted_at IS NULL
+	`
+
+	err := r.db.Raw(query, propertyID).Scan(summary).Error
+	return summary, err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// key of the advisory lock the saved search matcher holds while it runs
+const savedSearchMatcherLock = 7301
+
+type savedSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+func (r *savedSearchRepository) CreateSavedSearch(search *models.SavedSearch) error {
+	return r.db.Create(search).Error
+}
+
+func (r *savedSearchRepository) GetSavedSearchByID(id uuid.UUID) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	err := r.db.Where("id = ?", id).First(&search).Error
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *savedSearchRepository) GetSavedSearchesByUserID(userID uuid.UUID, page models.PageQuery) ([]*models.SavedSearch, error) {
+	var searches []*models.SavedSearch
+	err := paginate(r.db.Where("user_id = ?", userID), "saved_searches", page).Find(&searches).Error
+	return searches, err
+}
+
+func (r *savedSearchRepository) UpdateSavedSearch(search *models.SavedSearch) error {
+	return r.db.Save(search).Error
+}
+
+func (r *savedSearchRepository) DeleteSavedSearch(id uuid.UUID) error {
+	return r.db.Delete(&models.SavedSearch{}, id).Error
+}
+
+// returns the next batch of saved searches with alerts on, ordered by id so
+// the matcher can walk them all without holding them in memory
+func (r *savedSearchRepository) GetSavedSearchesWithAlerts(afterID uuid.UUID, limit int) ([]*models.SavedSearch, error) {
+	var searches []*models.SavedSearch
+	err := r.db.Preload("User").
+		Where("alerts_enabled = ? AND id > ?", true, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&searches).Error
+	return searches, err
+}
+
+// runs fn while holding a lock no other instance of the server can take at
+// the same time, and reports whether it did; it does not when another holds
+// the lock. The lock is released with the transaction it is taken in.
+func (r *savedSearchRepository) WithMatcherLock(fn func() error) (bool, error) {
+	locked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", savedSearchMatcherLock).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		return fn()
+	})
+	return locked, err
+}
+
+// records when the matcher last ran a saved search without touching updated_at
+func (r *savedSearchRepository) MarkSavedSearchRun(id uuid.UUID, runAt time.Time) error {
+	return r.db.Model(&models.SavedSearch{}).Where("id = ?", id).UpdateColumn("last_run_at", runAt).Error
+}
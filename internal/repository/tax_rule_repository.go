@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type taxRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRuleRepository(db *gorm.DB) TaxRuleRepository {
+	return &taxRuleRepository{db: db}
+}
+
+func (r *taxRuleRepository) CreateTaxRule(rule *models.TaxRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *taxRuleRepository) GetTaxRuleByID(id uuid.UUID) (*models.TaxRule, error) {
+	var rule models.TaxRule
+	err := r.db.Where("id = ?", id).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *taxRuleRepository) UpdateTaxRule(rule *models.TaxRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *taxRuleRepository) DeleteTaxRule(id uuid.UUID) error {
+	return r.db.Delete(&models.TaxRule{}, id).Error
+}
+
+func (r *taxRuleRepository) ListTaxRules(page models.PageQuery) ([]*models.TaxRule, error) {
+	var rules []*models.TaxRule
+	err := paginate(r.db, "tax_rules", page).Find(&rules).Error
+	return rules, err
+}
+
+// returns the active rules for a location, where an empty state or city on a
+// rule matches any value
+func (r *taxRuleRepository) GetMatchingTaxRules(country, state, city string) ([]*models.TaxRule, error) {
+	var rules []*models.TaxRule
+	err := r.db.
+		Where("is_active = ?", true).
+		Where("LOWER(country) = LOWER(?)", country).
+		Where("(state = '' OR LOWER(state) = LOWER(?))", state).
+		Where("(city = '' OR LOWER(city) = LOWER(?))", city).
+		Order("country, state, city, name").
+		Find(&rules).Error
+	return rules, err
+}
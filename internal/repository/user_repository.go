@@ -36,3 +36,7 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *userRepository) UpdateUser(user *models.User) error {
+	return r.db.Save(user).Error
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"airbnb-clone/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db: db}
+}
+
+func (r *wishlistRepository) CreateWishlist(wishlist *models.Wishlist) error {
+	return r.db.Create(wishlist).Error
+}
+
+// loads a wishlist with its items, newest first, and its collaborators
+func (r *wishlistRepository) withDetails() *gorm.DB {
+	return r.db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("wishlist_items.created_at DESC")
+		}).
+		Preload("Items.Property").
+		Preload("Collaborators.User")
+}
+
+func (r *wishlistRepository) GetWishlistByID(id uuid.UUID) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.withDetails().Where("id = ?", id).First(&wishlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) GetWishlistByShareToken(token string) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := r.withDetails().
+		Where("share_token = ? AND visibility = ?", token, models.WishlistVisibilityShared).
+		First(&wishlist).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// returns the wishlists the user owns or collaborates on
+func (r *wishlistRepository) GetWishlistsForUser(userID uuid.UUID, page models.PageQuery) ([]*models.Wishlist, error) {
+	var wishlists []*models.Wishlist
+	query := r.db.Preload("Items").
+		Where("owner_id = ? OR id IN (SELECT wishlist_id FROM wishlist_collaborators WHERE user_id = ?)", userID, userID)
+	err := paginate(query, "wishlists", page).Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (r *wishlistRepository) UpdateWishlist(wishlist *models.Wishlist) error {
+	return r.db.Omit(clause.Associations).Save(wishlist).Error
+}
+
+func (r *wishlistRepository) DeleteWishlist(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", id).Delete(&models.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", id).Delete(&models.WishlistCollaborator{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Wishlist{}, id).Error
+	})
+}
+
+func (r *wishlistRepository) AddItem(item *models.WishlistItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *wishlistRepository) GetItemByID(id uuid.UUID) (*models.WishlistItem, error) {
+	var item models.WishlistItem
+	err := r.db.Preload("Property").Where("id = ?", id).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) UpdateItem(item *models.WishlistItem) error {
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+func (r *wishlistRepository) DeleteItem(id uuid.UUID) error {
+	return r.db.Delete(&models.WishlistItem{}, id).Error
+}
+
+func (r *wishlistRepository) AddCollaborator(collaborator *models.WishlistCollaborator) error {
+	return r.db.Omit(clause.Associations).Create(collaborator).Error
+}
+
+func (r *wishlistRepository) RemoveCollaborator(wishlistID, userID uuid.UUID) error {
+	return r.db.Where("wishlist_id = ? AND user_id = ?", wishlistID, userID).Delete(&models.WishlistCollaborator{}).Error
+}
+
+// returns which of the given properties are in any wishlist the user owns
+func (r *wishlistRepository) GetFavoritedPropertyIDs(userID uuid.UUID, propertyIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var favorited []uuid.UUID
+	if len(propertyIDs) == 0 {
+		return favorited, nil
+	}
+
+	err := r.db.Model(&models.WishlistItem{}).
+		Distinct().
+		Joins("JOIN wishlists ON wishlists.id = wishlist_items.wishlist_id AND wishlists.deleted_at IS NULL").
+		Where("wishlists.owner_id = ? AND wishlist_items.property_id IN ?", userID, propertyIDs).
+		Pluck("wishlist_items.property_id", &favorited).Error
+	return favorited, err
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"airbnb-clone/internal/cache"
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// amenity ids are short slugs such as "wifi" or "hot_tub"
+var amenityIDPattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// the catalog is small, so it is cached whole under a single key
+const amenityCatalogKey = "catalog"
+
+type AmenityService struct {
+	amenityRepo repository.AmenityRepository
+	caches      *Caches
+}
+
+func NewAmenityService(amenityRepo repository.AmenityRepository, caches *Caches) *AmenityService {
+	return &AmenityService{
+		amenityRepo: amenityRepo,
+		caches:      caches,
+	}
+}
+
+// drops the cached catalog and every cached search page, since aliases decide
+// which amenities a search filter matches
+func (s *AmenityService) evictCatalog() {
+	evictCached(s.caches.Amenities, amenityCatalogKey)
+	invalidateCached(s.caches.Search)
+}
+
+// maps the unmapped amenities of listings that a new amenity or alias now
+// covers. The catalog change already succeeded, so a failure is only logged.
+func (s *AmenityService) remapProperties() {
+	count, err := s.amenityRepo.RemapPropertyAmenities()
+	if err != nil {
+		logger.Errorf("failed to remap property amenities: %v", err)
+		return
+	}
+	if count > 0 {
+		invalidateCached(s.caches.Properties)
+	}
+}
+
+func (s *AmenityService) CreateAmenity(req *models.AmenityCreateRequest) (*models.Amenity, error) {
+	amenity := &models.Amenity{
+		ID:       strings.ToLower(strings.TrimSpace(req.ID)),
+		Category: strings.TrimSpace(req.Category),
+		Icon:     strings.TrimSpace(req.Icon),
+		Names:    req.Names,
+		Aliases:  normalizeAliases(req.Aliases),
+	}
+
+	if err := s.validateAmenity(amenity); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.amenityRepo.GetAmenityByID(amenity.ID); err == nil {
+		return nil, errors.New("amenity already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get amenity: %w", err)
+	}
+
+	err := s.amenityRepo.CreateAmenity(amenity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create amenity: %w", err)
+	}
+
+	s.remapProperties()
+	s.evictCatalog()
+	return amenity, nil
+}
+
+func (s *AmenityService) GetAmenity(id string) (*models.Amenity, error) {
+	amenity, err := s.amenityRepo.GetAmenityByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("amenity not found")
+		}
+		return nil, fmt.Errorf("failed to get amenity: %w", err)
+	}
+
+	return amenity, nil
+}
+
+func (s *AmenityService) UpdateAmenity(id string, req *models.AmenityUpdateRequest) (*models.Amenity, error) {
+	amenity, err := s.GetAmenity(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Category != "" {
+		amenity.Category = strings.TrimSpace(req.Category)
+	}
+	if req.Icon != nil {
+		amenity.Icon = strings.TrimSpace(*req.Icon)
+	}
+	if req.Names != nil {
+		amenity.Names = req.Names
+	}
+	if req.Aliases != nil {
+		amenity.Aliases = normalizeAliases(req.Aliases)
+	}
+
+	if err := s.validateAmenity(amenity); err != nil {
+		return nil, err
+	}
+
+	err = s.amenityRepo.UpdateAmenity(amenity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update amenity: %w", err)
+	}
+
+	s.remapProperties()
+	s.evictCatalog()
+	return amenity, nil
+}
+
+// DeleteAmenity removes an amenity from the catalog. Amenities that listings
+// still use cannot be deleted.
+func (s *AmenityService) DeleteAmenity(id string) error {
+	if _, err := s.GetAmenity(id); err != nil {
+		return err
+	}
+
+	count, err := s.amenityRepo.CountPropertiesWithAmenity(id)
+	if err != nil {
+		return fmt.Errorf("failed to count properties with amenity: %w", err)
+	}
+	if count > 0 {
+		return errors.New("amenity is used by properties")
+	}
+
+	err = s.amenityRepo.DeleteAmenity(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete amenity: %w", err)
+	}
+
+	s.evictCatalog()
+	return nil
+}
+
+// ListAmenities returns the catalog, optionally only one category
+func (s *AmenityService) ListAmenities(category string) ([]*models.Amenity, error) {
+	amenities, err := s.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	if category == "" {
+		return amenities, nil
+	}
+
+	filtered := make([]*models.Amenity, 0, len(amenities))
+	for _, amenity := range amenities {
+		if amenity.Category == category {
+			filtered = append(filtered, amenity)
+		}
+	}
+	return filtered, nil
+}
+
+// GetUnmappedAmenities lists the amenities of older listings that no catalog
+// entry or alias matches yet
+func (s *AmenityService) GetUnmappedAmenities() ([]*models.UnmappedAmenity, error) {
+	unmapped, err := s.amenityRepo.GetUnmappedAmenities()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unmapped amenities: %w", err)
+	}
+	return unmapped, nil
+}
+
+func (s *AmenityService) catalog() ([]*models.Amenity, error) {
+	return cache.GetOrLoad(s.caches.Amenities, amenityCatalogKey, func() ([]*models.Amenity, error) {
+		amenities, err := s.amenityRepo.ListAmenities()
+		if err != nil {
+			logger.Errorf("failed to list amenities: %v", err)
+			return nil, err
+		}
+		return amenities, nil
+	})
+}
+
+// ResolveAmenities maps amenity ids and aliases, in any spelling, to catalog
+// ids. Duplicates are dropped and the order is kept. Values that match no
+// amenity are an error.
+func (s *AmenityService) ResolveAmenities(values []string) ([]string, error) {
+	resolved, unknown, err := s.resolveAmenities(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown amenities: %s", strings.Join(unknown, ", "))
+	}
+
+	return resolved, nil
+}
+
+// ResolveKnownAmenities is ResolveAmenities for criteria stored earlier,
+// where values that left the catalog since are dropped and returned
+func (s *AmenityService) ResolveKnownAmenities(values []string) ([]string, []string, error) {
+	return s.resolveAmenities(values)
+}
+
+func (s *AmenityService) resolveAmenities(values []string) ([]string, []string, error) {
+	if len(values) == 0 {
+		return values, nil, nil
+	}
+
+	amenities, err := s.catalog()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byKey := make(map[string]string, len(amenities))
+	for _, amenity := range amenities {
+		for _, alias := range amenity.Aliases {
+			byKey[alias] = amenity.ID
+		}
+	}
+	// an id always wins over another amenity's alias
+	for _, amenity := range amenities {
+		byKey[models.AmenityKey(amenity.ID)] = amenity.ID
+	}
+
+	resolved := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	var unknown []string
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		id, ok := byKey[models.AmenityKey(value)]
+		if !ok {
+			unknown = append(unknown, value)
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			resolved = append(resolved, id)
+		}
+	}
+
+	return resolved, unknown, nil
+}
+
+// IsUnknownAmenitiesError reports whether err was returned by ResolveAmenities
+// for values outside the catalog
+func IsUnknownAmenitiesError(err error) bool {
+	return strings.HasPrefix(err.Error(), "unknown amenities: ")
+}
+
+func (s *AmenityService) validateAmenity(amenity *models.Amenity) error {
+	if amenity.ID == "" {
+		return errors.New("amenity id is required")
+	}
+	if len(amenity.ID) > 50 || !amenityIDPattern.MatchString(amenity.ID) {
+		return errors.New("invalid amenity id")
+	}
+	if models.IsListingFieldAmenity(amenity.ID) {
+		return errors.New("amenity is a house rule or accessibility feature")
+	}
+	if amenity.Category == "" {
+		return errors.New("amenity category is required")
+	}
+	if strings.TrimSpace(amenity.Names[models.DefaultLocale]) == "" {
+		return errors.New("amenity needs an English name")
+	}
+
+	// an alias must not point at two amenities
+	amenities, err := s.catalog()
+	if err != nil {
+		return err
+	}
+	own := map[string]bool{models.AmenityKey(amenity.ID): true}
+	for _, alias := range amenity.Aliases {
+		own[alias] = true
+	}
+	for _, other := range amenities {
+		if other.ID == amenity.ID {
+			continue
+		}
+		if own[models.AmenityKey(other.ID)] {
+			return errors.New("amenity alias is used by another amenity")
+		}
+		for _, alias := range other.Aliases {
+			if own[alias] {
+				return errors.New("amenity alias is used by another amenity")
+			}
+		}
+	}
+
+	return nil
+}
+
+// stores aliases in the form they are compared in, without duplicates
+func normalizeAliases(aliases []string) []string {
+	normalized := make([]string, 0, len(aliases))
+	seen := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		key := models.AmenityKey(alias)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, key)
+		}
+	}
+	return normalized
+}
@@ -5,6 +5,8 @@ import (
 	"airbnb-clone/internal/repository"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,17 +14,67 @@ import (
 )
 
 type BookingService struct {
-	bookingRepo  repository.BookingRepository
-	propertyRepo repository.PropertyRepository
+	bookingRepo     repository.BookingRepository
+	propertyRepo    repository.PropertyRepository
+	taxRuleRepo     repository.TaxRuleRepository
+	revisionService *PropertyRevisionService
+	cohostService   *CoHostService
+	caches          *Caches
 }
 
-func NewBookingService(bookingRepo repository.BookingRepository, propertyRepo repository.PropertyRepository) *BookingService {
+func NewBookingService(bookingRepo repository.BookingRepository, propertyRepo repository.PropertyRepository, taxRuleRepo repository.TaxRuleRepository, revisionService *PropertyRevisionService, cohostService *CoHostService, caches *Caches) *BookingService {
 	return &BookingService{
-		bookingRepo:  bookingRepo,
-		propertyRepo: propertyRepo,
+		bookingRepo:     bookingRepo,
+		propertyRepo:    propertyRepo,
+		taxRuleRepo:     taxRuleRepo,
+		revisionService: revisionService,
+		cohostService:   cohostService,
+		caches:          caches,
 	}
 }
 
+// bookingPrice is the price breakdown for a stay
+type bookingPrice struct {
+	subtotal  float64
+	taxes     models.BookingTaxes
+	taxAmount float64
+	total     float64
+}
+
+// calculates the nightly subtotal and the occupancy taxes of the property's jurisdiction
+func (s *BookingService) calculatePrice(property *models.Property, checkIn, checkOut time.Time, guests int) (*bookingPrice, error) {
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+	price := &bookingPrice{
+		subtotal: float64(nights) * property.PricePerNight,
+		taxes:    models.BookingTaxes{},
+	}
+
+	rules, err := s.taxRuleRepo.GetMatchingTaxRules(property.Country, property.State, property.City)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		amount := rule.Amount(nights, property.PricePerNight, guests)
+		if amount == 0 {
+			continue
+		}
+		price.taxes = append(price.taxes, models.BookingTax{
+			TaxRuleID: rule.ID,
+			Name:      rule.Name,
+			Type:      rule.Type,
+			Rate:      rule.Rate,
+			Amount:    amount,
+		})
+		price.taxAmount += amount
+	}
+
+	price.taxAmount = math.Round(price.taxAmount*100) / 100
+	price.total = math.Round((price.subtotal+price.taxAmount)*100) / 100
+
+	return price, nil
+}
+
 func (s *BookingService) CreateBooking(guestID uuid.UUID, req *models.BookingCreateRequest) (*models.BookingResponse, error) {
 	// validate dates
 	if req.CheckOut.Before(req.CheckIn) || req.CheckOut.Equal(req.CheckIn) {
@@ -71,8 +123,11 @@ func (s *BookingService) CreateBooking(guestID uuid.UUID, req *models.BookingCre
 		return nil, errors.New("booking must be for at least one night")
 	}
 
-	// calculate total price
-	totalPrice := float64(nights) * property.PricePerNight
+	// calculate total price including occupancy taxes
+	price, err := s.calculatePrice(property, req.CheckIn, req.CheckOut, req.Guests)
+	if err != nil {
+		return nil, err
+	}
 
 	booking := &models.Booking{
 		PropertyID: req.PropertyID,
@@ -80,16 +135,22 @@ func (s *BookingService) CreateBooking(guestID uuid.UUID, req *models.BookingCre
 		CheckIn:    req.CheckIn,
 		CheckOut:   req.CheckOut,
 		Guests:     req.Guests,
-		TotalPrice: totalPrice,
+		Subtotal:   price.subtotal,
+		TaxAmount:  price.taxAmount,
+		Taxes:      price.taxes,
+		TotalPrice: price.total,
 		Currency:   property.Currency,
 		Status:     models.BookingStatusPending,
 		Notes:      req.Notes,
 	}
+	// the listing as the guest saw it when booking
+	booking.PropertyRevisionID = s.revisionService.latestRevisionID(req.PropertyID)
 
 	err = s.bookingRepo.CreateBooking(booking)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create booking: %w", err)
 	}
+	invalidateCached(s.caches.Search)
 
 	createdBooking, err := s.bookingRepo.GetBookingByID(booking.ID)
 	if err != nil {
@@ -108,11 +169,140 @@ func (s *BookingService) GetBooking(bookingID uuid.UUID, userID uuid.UUID, userR
 		return nil, fmt.Errorf("failed to get booking: %w", err)
 	}
 
-	if userRole != "admin" && booking.GuestID != userID && booking.Property.HostID != userID {
+	access := s.bookingAccess(booking, userID, userRole)
+	if access == bookingAccessNone {
 		return nil, errors.New("unauthorized: you can only view your own bookings")
 	}
 
-	return booking.ToResponse(), nil
+	return bookingResponse(booking, access), nil
+}
+
+func (s *BookingService) GetBookingReceipt(bookingID uuid.UUID, userID uuid.UUID, userRole string) (*models.BookingReceipt, error) {
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("booking not found")
+		}
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	// the receipt shows what the host earns
+	if s.bookingAccess(booking, userID, userRole) != bookingAccessFull {
+		return nil, errors.New("unauthorized: you can only view your own bookings")
+	}
+
+	// the booking only preloads the property, so fetch it again with its host
+	property, err := s.propertyRepo.GetPropertyByID(booking.PropertyID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get property: %w", err)
+	}
+	if property == nil {
+		property = &booking.Property
+	}
+
+	return buildReceipt(booking, property), nil
+}
+
+// how much of a booking a user may see
+type bookingAccess int
+
+const (
+	bookingAccessNone bookingAccess = iota
+	// the dates, for co-hosts who manage the calendar
+	bookingAccessCalendar
+	// everything but the price, for co-hosts who respond to bookings
+	bookingAccessDetails
+	// for admins, the guest, the host and co-hosts who view earnings
+	bookingAccessFull
+)
+
+func (s *BookingService) bookingAccess(booking *models.Booking, userID uuid.UUID, userRole string) bookingAccess {
+	if userRole == "admin" || booking.GuestID == userID {
+		return bookingAccessFull
+	}
+	return s.propertyBookingAccess(&booking.Property, userID)
+}
+
+func (s *BookingService) propertyBookingAccess(property *models.Property, userID uuid.UUID) bookingAccess {
+	switch {
+	case s.cohostService.canManage(property, userID, models.CoHostPermissionViewEarnings):
+		return bookingAccessFull
+	case s.cohostService.canManage(property, userID, models.CoHostPermissionRespondToBookings):
+		return bookingAccessDetails
+	case s.cohostService.canManage(property, userID, models.CoHostPermissionManageCalendar):
+		return bookingAccessCalendar
+	}
+	return bookingAccessNone
+}
+
+// the booking with what the access does not cover left out
+func bookingResponse(booking *models.Booking, access bookingAccess) *models.BookingResponse {
+	response := booking.ToResponse()
+	if access < bookingAccessFull {
+		response.BookingPrice = nil
+	}
+	if access < bookingAccessDetails {
+		response.Notes = ""
+		response.Guest = nil
+	}
+	return response
+}
+
+func buildReceipt(booking *models.Booking, property *models.Property) *models.BookingReceipt {
+	checkIn := time.Date(booking.CheckIn.Year(), booking.CheckIn.Month(), booking.CheckIn.Day(), 0, 0, 0, 0, time.UTC)
+	nightCount := int(booking.CheckOut.Sub(booking.CheckIn).Hours() / 24)
+	if nightCount < 1 {
+		nightCount = 1
+	}
+
+	// bookings made before taxes were tracked only have a total
+	subtotal := booking.Subtotal
+	if subtotal == 0 {
+		subtotal = booking.TotalPrice - booking.TaxAmount
+	}
+	nightlyRate := math.Round(subtotal/float64(nightCount)*100) / 100
+
+	taxes := make([]models.ReceiptTaxLine, len(booking.Taxes))
+	for i, tax := range booking.Taxes {
+		taxes[i] = models.ReceiptTaxLine{Name: tax.Name, Amount: tax.Amount}
+	}
+	nights := make([]models.ReceiptNight, nightCount)
+	for i := range nights {
+		nights[i] = models.ReceiptNight{Date: checkIn.AddDate(0, 0, i), Rate: nightlyRate}
+	}
+
+	return &models.BookingReceipt{
+		ReceiptNumber: "RCPT-" + strings.ToUpper(booking.ID.String()[:8]),
+		IssuedAt:      time.Now().UTC(),
+		BookingID:     booking.ID,
+		BookedAt:      booking.CreatedAt,
+		Guest: models.ReceiptParty{
+			Name:  strings.TrimSpace(booking.Guest.FirstName + " " + booking.Guest.LastName),
+			Email: booking.Guest.Email,
+			Phone: booking.Guest.Phone,
+		},
+		Host: models.ReceiptParty{
+			Name:  strings.TrimSpace(property.Host.FirstName + " " + property.Host.LastName),
+			Email: property.Host.Email,
+		},
+		PropertyTitle: property.Title,
+		PropertyAddress: []string{
+			property.Address,
+			strings.TrimSpace(fmt.Sprintf("%s, %s %s", property.City, property.State, property.ZipCode)),
+			property.Country,
+		},
+		CheckIn:       booking.CheckIn,
+		CheckOut:      booking.CheckOut,
+		Guests:        booking.Guests,
+		Nights:        nights,
+		Subtotal:      subtotal,
+		Taxes:         taxes,
+		TaxTotal:      booking.TaxAmount,
+		Total:         booking.TotalPrice,
+		Currency:      booking.Currency,
+		BookingStatus: booking.Status,
+		PaymentStatus: models.PaymentStatusFor(booking.Status),
+	}
 }
 
 func (s *BookingService) UpdateBooking(bookingID, userID uuid.UUID, userRole string, req *models.BookingUpdateRequest) (*models.BookingResponse, error) {
@@ -129,7 +319,7 @@ func (s *BookingService) UpdateBooking(bookingID, userID uuid.UUID, userRole str
 		canUpdate = true
 	} else if booking.GuestID == userID {
 		canUpdate = true
-	} else if booking.Property.HostID == userID {
+	} else if s.cohostService.canManage(&booking.Property, userID, models.CoHostPermissionRespondToBookings) {
 		canUpdate = true
 	}
 
@@ -172,11 +362,9 @@ func (s *BookingService) UpdateBooking(bookingID, userID uuid.UUID, userRole str
 			}
 		}
 
-		// Update dates and recalculate price
+		// Update dates, the price is recalculated below
 		booking.CheckIn = req.CheckIn
 		booking.CheckOut = req.CheckOut
-		nights := int(req.CheckOut.Sub(req.CheckIn).Hours() / 24)
-		booking.TotalPrice = float64(nights) * booking.Property.PricePerNight
 	}
 
 	if req.Guests > 0 {
@@ -195,12 +383,25 @@ func (s *BookingService) UpdateBooking(bookingID, userID uuid.UUID, userRole str
 		booking.Guests = req.Guests
 	}
 
+	if (!req.CheckIn.IsZero() && !req.CheckOut.IsZero()) || req.Guests > 0 {
+		price, err := s.calculatePrice(&booking.Property, booking.CheckIn, booking.CheckOut, booking.Guests)
+		if err != nil {
+			return nil, err
+		}
+		booking.Subtotal = price.subtotal
+		booking.TaxAmount = price.taxAmount
+		booking.Taxes = price.taxes
+		booking.TotalPrice = price.total
+	}
+
 	if req.Status != "" {
+		isHost := s.cohostService.canManage(&booking.Property, userID, models.CoHostPermissionRespondToBookings)
+
 		// Status changes have specific rules
 		switch req.Status {
 		case models.BookingStatusConfirmed:
 			// Only hosts and admins can confirm bookings
-			if booking.Property.HostID != userID && userRole != "admin" {
+			if !isHost && userRole != "admin" {
 				return nil, errors.New("only the host can confirm bookings")
 			}
 			if booking.Status != models.BookingStatusPending {
@@ -210,14 +411,14 @@ func (s *BookingService) UpdateBooking(bookingID, userID uuid.UUID, userRole str
 			// Guests can cancel pending bookings, hosts and admins can cancel any
 			if booking.GuestID == userID && booking.Status == models.BookingStatusPending {
 				// Guest cancelling pending booking - allowed
-			} else if booking.Property.HostID == userID || userRole == "admin" {
+			} else if isHost || userRole == "admin" {
 				// Host or admin - allowed
 			} else {
 				return nil, errors.New("unauthorized to cancel this booking")
 			}
 		case models.BookingStatusCompleted:
 			// Only admins and hosts can mark as completed, and only after check-out date
-			if booking.Property.HostID != userID && userRole != "admin" {
+			if !isHost && userRole != "admin" {
 				return nil, errors.New("only the host can mark bookings as completed")
 			}
 			if booking.Status != models.BookingStatusConfirmed {
@@ -240,8 +441,9 @@ func (s *BookingService) UpdateBooking(bookingID, userID uuid.UUID, userRole str
 	if err != nil {
 		return nil, fmt.Errorf("failed to update booking: %w", err)
 	}
+	invalidateCached(s.caches.Search)
 
-	return booking.ToResponse(), nil
+	return bookingResponse(booking, s.bookingAccess(booking, userID, userRole)), nil
 }
 
 func (s *BookingService) CancelBooking(bookingID, userID uuid.UUID, userRole string) (*models.BookingResponse, error) {
@@ -260,8 +462,8 @@ func (s *BookingService) CancelBooking(bookingID, userID uuid.UUID, userRole str
 	} else if booking.GuestID == userID {
 		// Guests can cancel their own bookings
 		canCancel = true
-	} else if booking.Property.HostID == userID {
-		// Hosts can cancel bookings for their properties
+	} else if s.cohostService.canManage(&booking.Property, userID, models.CoHostPermissionRespondToBookings) {
+		// Hosts and their co-hosts can cancel bookings for their properties
 		canCancel = true
 	}
 
@@ -282,65 +484,64 @@ func (s *BookingService) CancelBooking(bookingID, userID uuid.UUID, userRole str
 	if err != nil {
 		return nil, fmt.Errorf("failed to cancel booking: %w", err)
 	}
+	invalidateCached(s.caches.Search)
 
-	return booking.ToResponse(), nil
+	return bookingResponse(booking, s.bookingAccess(booking, userID, userRole)), nil
 }
 
-func (s *BookingService) GetUserBookings(userID uuid.UUID, page, limit int) ([]*models.BookingResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
+func (s *BookingService) GetUserBookings(userID uuid.UUID, page, limit int, cursor string) ([]*models.BookingResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
 	}
-	offset := (page - 1) * limit
 
-	bookings, err := s.bookingRepo.GetBookingByUserID(userID, offset, limit)
+	bookings, err := s.bookingRepo.GetBookingByUserID(userID, pageQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user bookings: %w", err)
+		return nil, "", fmt.Errorf("failed to get user bookings: %w", err)
 	}
+	bookings, nextCursor := models.TrimPage(bookings, pageQuery, (*models.Booking).PageCursor)
 
 	responses := make([]*models.BookingResponse, len(bookings))
 	for i, booking := range bookings {
 		responses[i] = booking.ToResponse()
 	}
 
-	return responses, nil
+	return responses, nextCursor, nil
 }
 
-func (s *BookingService) GetPropertyBookings(propertyID, hostID uuid.UUID, page, limit int) ([]*models.BookingResponse, error) {
-	// Verify that the user is the host of this property
+func (s *BookingService) GetPropertyBookings(propertyID, hostID uuid.UUID, page, limit int, cursor string) ([]*models.BookingResponse, string, error) {
+	// Verify that the user is the host of this property or a co-host who
+	// handles its bookings
 	property, err := s.propertyRepo.GetPropertyByID(propertyID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("property not found")
+			return nil, "", errors.New("property not found")
 		}
-		return nil, fmt.Errorf("failed to get property: %w", err)
+		return nil, "", fmt.Errorf("failed to get property: %w", err)
 	}
 
-	if property.HostID != hostID {
-		return nil, errors.New("unauthorized: you can only view bookings for your own properties")
+	access := s.propertyBookingAccess(property, hostID)
+	if access == bookingAccessNone {
+		return nil, "", errors.New("unauthorized: you can only view bookings for your own properties")
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
 	}
-	offset := (page - 1) * limit
 
-	bookings, err := s.bookingRepo.GetBookingByPropertyID(propertyID, offset, limit)
+	bookings, err := s.bookingRepo.GetBookingByPropertyID(propertyID, pageQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get property bookings: %w", err)
+		return nil, "", fmt.Errorf("failed to get property bookings: %w", err)
 	}
+	bookings, nextCursor := models.TrimPage(bookings, pageQuery, (*models.Booking).PageCursor)
 
 	responses := make([]*models.BookingResponse, len(bookings))
 	for i, booking := range bookings {
-		responses[i] = booking.ToResponse()
+		responses[i] = bookingResponse(booking, access)
 	}
 
-	return responses, nil
+	return responses, nextCursor, nil
 }
 
 
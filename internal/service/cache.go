@@ -0,0 +1,46 @@
+package service
+
+import (
+	"airbnb-clone/internal/cache"
+	"airbnb-clone/internal/config"
+	"airbnb-clone/internal/logger"
+	"time"
+)
+
+// Caches holds the cache namespaces shared by the services
+type Caches struct {
+	Properties  *cache.Namespace
+	Search      *cache.Namespace
+	Users       *cache.Namespace
+	Ratings     *cache.Namespace
+	Suggestions *cache.Namespace
+	Amenities   *cache.Namespace
+}
+
+// creates the cache namespaces with the configured TTLs
+func NewCaches(c *cache.Cache, cfg config.CacheConfig) *Caches {
+	return &Caches{
+		Properties: c.Namespace("property", time.Duration(cfg.PropertyTTLSeconds)*time.Second),
+		Search:     c.Namespace("search", time.Duration(cfg.SearchTTLSeconds)*time.Second),
+		Users:      c.Namespace("user", time.Duration(cfg.UserTTLSeconds)*time.Second),
+		Ratings:    c.Namespace("rating", time.Duration(cfg.RatingTTLSeconds)*time.Second),
+		// every prefix is cached, rarely typed ones simply expire
+		Suggestions: c.Namespace("suggest", time.Duration(cfg.SuggestTTLSeconds)*time.Second),
+		Amenities:   c.Namespace("amenity", time.Duration(cfg.AmenityTTLSeconds)*time.Second),
+	}
+}
+
+// removes a cached entry after a mutation. A failure leaves the old value
+// visible until its TTL runs out, so it is logged rather than returned.
+func evictCached(namespace *cache.Namespace, id string) {
+	if err := namespace.Evict(id); err != nil {
+		logger.Errorf("failed to evict cached entry %s: %v", id, err)
+	}
+}
+
+// drops every entry of a namespace after a change that can affect any of them
+func invalidateCached(namespace *cache.Namespace) {
+	if err := namespace.InvalidateAll(); err != nil {
+		logger.Errorf("failed to invalidate cache: %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/notification"
+	"airbnb-clone/internal/repository"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// how long a co-host invitation can be accepted
+const coHostInvitationTTL = 7 * 24 * time.Hour
+
+type CoHostService struct {
+	cohostRepo   repository.CoHostRepository
+	propertyRepo repository.PropertyRepository
+	userRepo     repository.UserRepository
+	notifier     notification.Notifier
+}
+
+func NewCoHostService(cohostRepo repository.CoHostRepository, propertyRepo repository.PropertyRepository, userRepo repository.UserRepository, notifier notification.Notifier) *CoHostService {
+	return &CoHostService{
+		cohostRepo:   cohostRepo,
+		propertyRepo: propertyRepo,
+		userRepo:     userRepo,
+		notifier:     notifier,
+	}
+}
+
+// canManage reports whether the user is the host of the property or a
+// co-host with any of the permissions. Every check that used to compare the
+// user to the host goes through it.
+func (s *CoHostService) canManage(property *models.Property, userID uuid.UUID, permissions ...models.CoHostPermission) bool {
+	if property.HostID == userID {
+		return true
+	}
+	if userID == uuid.Nil {
+		return false
+	}
+
+	cohost, err := s.cohostRepo.GetCoHost(property.ID, userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Errorf("failed to get co-host of property %s: %v", property.ID, err)
+		}
+		return false
+	}
+	return cohost.Can(permissions...)
+}
+
+// returns the property if the user is its host, or an admin when allowed
+func (s *CoHostService) getHostedProperty(propertyID, userID uuid.UUID, isAdmin bool) (*models.Property, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	if !isAdmin && property.HostID != userID {
+		return nil, errors.New("unauthorized: only the host can manage co-hosts")
+	}
+
+	return property, nil
+}
+
+// checks the permissions against the supported ones and drops duplicates
+func normalizeCoHostPermissions(permissions []string) (pq.StringArray, error) {
+	normalized := make(pq.StringArray, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if permission == "" || seen[permission] {
+			continue
+		}
+		if !models.IsValidCoHostPermission(models.CoHostPermission(permission)) {
+			return nil, errors.New("invalid co-host permission")
+		}
+		seen[permission] = true
+		normalized = append(normalized, permission)
+	}
+	if len(normalized) == 0 {
+		return nil, errors.New("co-hosts need at least one permission")
+	}
+	return normalized, nil
+}
+
+// hashCoHostToken returns what is stored in place of an invitation token
+func hashCoHostToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetCoHosts returns the co-hosts of a property to its host, its co-hosts or
+// an admin
+func (s *CoHostService) GetCoHosts(propertyID, userID uuid.UUID, isAdmin bool) ([]*models.CoHostResponse, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	cohosts, err := s.cohostRepo.GetCoHostsByPropertyID(propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get co-hosts: %w", err)
+	}
+
+	allowed := isAdmin || property.HostID == userID
+	for _, cohost := range cohosts {
+		if cohost.UserID == userID {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, errors.New("unauthorized: you can only view your own properties")
+	}
+
+	responses := make([]*models.CoHostResponse, len(cohosts))
+	for i, cohost := range cohosts {
+		responses[i] = cohost.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// InviteCoHost emails a link to become a co-host of the property. A new
+// invitation to the same email replaces the one still pending.
+func (s *CoHostService) InviteCoHost(propertyID, hostID uuid.UUID, req *models.CoHostInvitationRequest) (*models.CoHostInvitationResponse, error) {
+	// the code to accept is only sent by email, there is no other way to it
+	if !s.notifier.Delivers() {
+		return nil, errors.New("invitations cannot be sent, email delivery is not configured")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, errors.New("invalid email")
+	}
+
+	permissions, err := normalizeCoHostPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	property, err := s.getHostedProperty(propertyID, hostID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(property.Host.Email, email) {
+		return nil, errors.New("the host cannot be a co-host")
+	}
+	if user, err := s.userRepo.GetByEmail(email); err == nil {
+		if _, err := s.cohostRepo.GetCoHost(propertyID, user.ID); err == nil {
+			return nil, errors.New("user is already a co-host")
+		}
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.CoHostInvitation{
+		PropertyID:  propertyID,
+		Email:       email,
+		Permissions: permissions,
+		TokenHash:   hashCoHostToken(token),
+		InvitedByID: hostID,
+		ExpiresAt:   time.Now().Add(coHostInvitationTTL),
+	}
+	if err := s.cohostRepo.CreateInvitation(invitation); err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	hostName := strings.TrimSpace(property.Host.FirstName + " " + property.Host.LastName)
+	err = s.notifier.Notify(&notification.Notification{
+		Type:    notification.TypeCoHostInvitation,
+		Email:   email,
+		Subject: fmt.Sprintf("%s invited you to co-host %q", hostName, property.Title),
+		Body:    fmt.Sprintf("Use this code to accept the invitation before %s: %s", invitation.ExpiresAt.Format("January 2, 2006"), token),
+		Data: map[string]interface{}{
+			"property_id":   propertyID,
+			"invitation_id": invitation.ID,
+			"token":         token,
+			"permissions":   []string(permissions),
+		},
+	})
+	if err != nil {
+		// the token is only in the email, so the invitation cannot be used
+		logger.Errorf("failed to send co-host invitation %s: %v", invitation.ID, err)
+		return nil, errors.New("failed to send invitation")
+	}
+
+	return invitation.ToResponse(), nil
+}
+
+// GetInvitations returns the pending invitations of a property to its host
+// or an admin
+func (s *CoHostService) GetInvitations(propertyID, userID uuid.UUID, isAdmin bool) ([]*models.CoHostInvitationResponse, error) {
+	if _, err := s.getHostedProperty(propertyID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.cohostRepo.GetPendingInvitations(propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	responses := make([]*models.CoHostInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = invitation.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// RevokeInvitation deletes a pending invitation so its link stops working
+func (s *CoHostService) RevokeInvitation(propertyID, invitationID, hostID uuid.UUID) error {
+	if _, err := s.getHostedProperty(propertyID, hostID, false); err != nil {
+		return err
+	}
+
+	if err := s.cohostRepo.DeleteInvitation(propertyID, invitationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invitation not found")
+		}
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+
+	return nil
+}
+
+// AcceptInvitation makes the user a co-host of the property the token was
+// sent for. The invitation must have been sent to the email of the user.
+func (s *CoHostService) AcceptInvitation(userID uuid.UUID, req *models.CoHostAcceptRequest) (*models.CoHostResponse, error) {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return nil, errors.New("invitation not found")
+	}
+
+	invitation, err := s.cohostRepo.GetInvitationByTokenHash(hashCoHostToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invitation not found")
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, errors.New("invitation has already been accepted")
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return nil, errors.New("invitation has expired")
+	}
+	if invitation.Property.ID == uuid.Nil {
+		return nil, errors.New("property not found")
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, errors.New("this invitation was sent to another email address")
+	}
+	if invitation.Property.HostID == userID {
+		return nil, errors.New("the host cannot be a co-host")
+	}
+
+	acceptedAt := time.Now()
+	invitation.AcceptedAt = &acceptedAt
+	cohost := &models.PropertyCoHost{
+		PropertyID:  invitation.PropertyID,
+		UserID:      userID,
+		Permissions: invitation.Permissions,
+		InvitedByID: invitation.InvitedByID,
+	}
+	if err := s.cohostRepo.AcceptInvitation(invitation, cohost); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invitation has already been accepted")
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	cohost.User = *user
+	return cohost.ToResponse(), nil
+}
+
+// UpdateCoHost replaces the permissions of a co-host
+func (s *CoHostService) UpdateCoHost(propertyID, cohostID, hostID uuid.UUID, req *models.CoHostUpdateRequest) (*models.CoHostResponse, error) {
+	permissions, err := normalizeCoHostPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getHostedProperty(propertyID, hostID, false); err != nil {
+		return nil, err
+	}
+
+	if err := s.cohostRepo.UpdateCoHostPermissions(propertyID, cohostID, permissions); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("co-host not found")
+		}
+		return nil, fmt.Errorf("failed to update co-host: %w", err)
+	}
+
+	cohost, err := s.cohostRepo.GetCoHost(propertyID, cohostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get co-host: %w", err)
+	}
+
+	return cohost.ToResponse(), nil
+}
+
+// RemoveCoHost removes a co-host. The host can remove anyone and co-hosts
+// can remove themselves to stop managing the property.
+func (s *CoHostService) RemoveCoHost(propertyID, cohostID, userID uuid.UUID) error {
+	if cohostID != userID {
+		if _, err := s.getHostedProperty(propertyID, userID, false); err != nil {
+			return err
+		}
+	}
+
+	if err := s.cohostRepo.RemoveCoHost(propertyID, cohostID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("co-host not found")
+		}
+		return fmt.Errorf("failed to remove co-host: %w", err)
+	}
+
+	return nil
+}
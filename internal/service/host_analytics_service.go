@@ -0,0 +1,152 @@
+package service
+
+import (
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// days covered when no period is given
+	defaultAnalyticsDays = 30
+	// longest period analytics are computed for
+	maxAnalyticsDays = 366
+)
+
+type HostAnalyticsService struct {
+	analyticsRepo repository.HostAnalyticsRepository
+	propertyRepo  repository.PropertyRepository
+	cohostService *CoHostService
+}
+
+func NewHostAnalyticsService(analyticsRepo repository.HostAnalyticsRepository, propertyRepo repository.PropertyRepository, cohostService *CoHostService) *HostAnalyticsService {
+	return &HostAnalyticsService{
+		analyticsRepo: analyticsRepo,
+		propertyRepo:  propertyRepo,
+		cohostService: cohostService,
+	}
+}
+
+// returns the properties the analytics cover: the one asked for, or all the
+// user hosts or may see the earnings of as a co-host
+func (s *HostAnalyticsService) getPropertyIDs(userID uuid.UUID, propertyID *uuid.UUID) ([]uuid.UUID, error) {
+	if propertyID == nil {
+		ids, err := s.analyticsRepo.GetManagedPropertyIDs(userID, models.CoHostPermissionViewEarnings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get properties: %w", err)
+		}
+		return ids, nil
+	}
+
+	property, err := s.propertyRepo.GetPropertyByID(*propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	if !s.cohostService.canManage(property, userID, models.CoHostPermissionViewEarnings) {
+		return nil, errors.New("unauthorized: you can only view analytics for your own properties")
+	}
+
+	return []uuid.UUID{property.ID}, nil
+}
+
+// GetAnalytics returns how the properties of the host performed over the
+// period, by default the last 30 days. Rates are fractions between 0 and 1.
+func (s *HostAnalyticsService) GetAnalytics(userID uuid.UUID, req *models.HostAnalyticsRequest) (*models.HostAnalytics, error) {
+	to := req.To
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	from := req.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, 1-defaultAnalyticsDays)
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+
+	if from.After(to) {
+		return nil, errors.New("from must not be after to")
+	}
+	// the end is exclusive in the queries
+	end := to.AddDate(0, 0, 1)
+	if end.Sub(from) > maxAnalyticsDays*24*time.Hour {
+		return nil, fmt.Errorf("the period cannot be longer than %d days", maxAnalyticsDays)
+	}
+
+	propertyIDs, err := s.getPropertyIDs(userID, req.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &models.HostAnalytics{
+		From:          from,
+		To:            to,
+		PropertyID:    req.PropertyID,
+		PropertyCount: len(propertyIDs),
+		Revenue:       []*models.CurrencyRevenue{},
+		RatingTrend:   []*models.RatingTrendPoint{},
+	}
+	if len(propertyIDs) == 0 {
+		return analytics, nil
+	}
+
+	occupancy, err := s.analyticsRepo.GetOccupancy(propertyIDs, from, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count available nights: %w", err)
+	}
+	analytics.AvailableNights = occupancy.AvailableNights
+	analytics.BookedNights = occupancy.BookedNights
+	if occupancy.AvailableNights > 0 {
+		analytics.OccupancyRate = roundRate(float64(occupancy.BookedNights) / float64(occupancy.AvailableNights))
+	}
+
+	revenue, err := s.analyticsRepo.GetRevenue(propertyIDs, from, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum revenue: %w", err)
+	}
+	for _, row := range revenue {
+		row.Revenue = math.Round(row.Revenue*100) / 100
+		if row.BookedNights > 0 {
+			row.AverageDailyRate = math.Round(row.Revenue/float64(row.BookedNights)*100) / 100
+		}
+		analytics.Revenue = append(analytics.Revenue, row)
+	}
+
+	activity, err := s.analyticsRepo.GetBookingActivity(propertyIDs, from, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count bookings: %w", err)
+	}
+	analytics.Bookings = activity.Bookings
+	analytics.CancelledBookings = activity.CancelledBookings
+	analytics.AverageLeadDays = math.Round(activity.AverageLeadDays*10) / 10
+	if activity.Bookings > 0 {
+		analytics.CancellationRate = roundRate(float64(activity.CancelledBookings) / float64(activity.Bookings))
+	}
+
+	trend, err := s.analyticsRepo.GetRatingTrend(propertyIDs, from, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating trend: %w", err)
+	}
+	for _, point := range trend {
+		point.AverageRating = math.Round(point.AverageRating*100) / 100
+		analytics.RatingTrend = append(analytics.RatingTrend, point)
+	}
+
+	return analytics, nil
+}
+
+// rounds a fraction to four decimals, i.e. hundredths of a percent
+func roundRate(rate float64) float64 {
+	return math.Round(rate*10000) / 10000
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/notification"
+	"airbnb-clone/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultModerationPageSize = 20
+	maxModerationPageSize     = 100
+	// longest notes a moderator can leave on a decision
+	maxModerationNotesLength = 2000
+)
+
+type ModerationService struct {
+	moderationRepo repository.ModerationRepository
+	propertyRepo   repository.PropertyRepository
+	imageService   *PropertyImageService
+	cohostService  *CoHostService
+	notifier       notification.Notifier
+	caches         *Caches
+}
+
+func NewModerationService(moderationRepo repository.ModerationRepository, propertyRepo repository.PropertyRepository, imageService *PropertyImageService, cohostService *CoHostService, notifier notification.Notifier, caches *Caches) *ModerationService {
+	return &ModerationService{
+		moderationRepo: moderationRepo,
+		propertyRepo:   propertyRepo,
+		imageService:   imageService,
+		cohostService:  cohostService,
+		notifier:       notifier,
+		caches:         caches,
+	}
+}
+
+// GetQueue returns the new listings and the changes to approved listings
+// waiting for a moderator, longest waiting first
+func (s *ModerationService) GetQueue(req *models.ModerationQueueRequest) (*models.ModerationQueueResponse, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	req.City = strings.TrimSpace(req.City)
+	req.Country = strings.TrimSpace(req.Country)
+	req.Type = strings.TrimSpace(req.Type)
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultModerationPageSize
+	}
+	if req.Limit > maxModerationPageSize {
+		req.Limit = maxModerationPageSize
+	}
+
+	properties, total, err := s.moderationRepo.GetPendingProperties(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation queue: %w", err)
+	}
+
+	propertyIDs := make([]uuid.UUID, len(properties))
+	for i, property := range properties {
+		propertyIDs[i] = property.ID
+	}
+	rejections, err := s.moderationRepo.CountRejections(propertyIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rejections: %w", err)
+	}
+
+	items := make([]*models.ModerationQueueItem, len(properties))
+	for i, property := range properties {
+		items[i] = &models.ModerationQueueItem{
+			PropertyResponse:   property.ToHostResponse(),
+			PreviousRejections: rejections[property.ID],
+		}
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	return &models.ModerationQueueResponse{
+		Properties: items,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// returns the property if it or changes to it are waiting for approval.
+// changesVersion is the version of the changes the moderator reviewed, if
+// they said.
+func (s *ModerationService) getPendingProperty(propertyID uuid.UUID, changesVersion *int) (*models.Property, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	if property.Status != models.PropertyStatusPending && property.PendingChanges == nil {
+		return nil, errors.New("only pending properties can be moderated")
+	}
+	if changesVersion != nil && (property.PendingChanges == nil || property.PendingChanges.Version != *changesVersion) {
+		return nil, errors.New("the listing changed since it was reviewed")
+	}
+
+	return property, nil
+}
+
+// ApproveProperty makes a pending listing active, or applies the pending
+// changes to an approved listing
+func (s *ModerationService) ApproveProperty(propertyID, reviewerID uuid.UUID, req *models.PropertyApproveRequest) (*models.PropertyResponse, error) {
+	notes := strings.TrimSpace(req.Notes)
+	if len(notes) > maxModerationNotesLength {
+		return nil, errors.New("moderation notes are too long")
+	}
+
+	property, err := s.getPendingProperty(propertyID, req.ChangesVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &models.PropertyModerationDecision{
+		PropertyID: propertyID,
+		ReviewerID: reviewerID,
+		Decision:   models.ModerationDecisionApproved,
+		Notes:      notes,
+	}
+	subject := fmt.Sprintf("Your listing \"%s\" was approved", property.Title)
+
+	if changes := property.PendingChanges; changes != nil {
+		// the images are replaced along with the decision
+		if changes.Images != nil {
+			if changes.Images, err = normalizeImageURLs(changes.Images); err != nil {
+				return nil, err
+			}
+		}
+
+		changes.ApplyTo(property)
+		property.PendingChanges = nil
+		decision.Changes = changes
+		subject = fmt.Sprintf("The changes to your listing \"%s\" were approved", property.Title)
+	} else {
+		now := time.Now()
+		property.Status = models.PropertyStatusActive
+		property.ApprovedAt = &now
+	}
+
+	if err := s.recordDecision(property, decision, models.PropertyRevisionApproved); err != nil {
+		return nil, err
+	}
+	s.evictProperty(propertyID)
+
+	s.notifyHost(property, decision, subject)
+
+	return property.ToHostResponse(), nil
+}
+
+// RejectProperty sends a pending listing back to its host with the reasons.
+// Rejected changes to an approved listing are dropped and the listing stays
+// live as it was.
+func (s *ModerationService) RejectProperty(propertyID, reviewerID uuid.UUID, req *models.PropertyRejectRequest) (*models.PropertyResponse, error) {
+	notes := strings.TrimSpace(req.Notes)
+	if len(notes) > maxModerationNotesLength {
+		return nil, errors.New("moderation notes are too long")
+	}
+
+	codes := make([]string, 0, len(req.ReasonCodes))
+	seen := make(map[string]bool, len(req.ReasonCodes))
+	for _, code := range req.ReasonCodes {
+		code = strings.TrimSpace(code)
+		if _, ok := models.LookupRejectionReason(code); !ok {
+			return nil, fmt.Errorf("unknown rejection reason: %s", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("at least one rejection reason is required")
+	}
+	if seen[models.RejectionReasonOther] && notes == "" {
+		return nil, errors.New("notes are required when the reason is other")
+	}
+
+	property, err := s.getPendingProperty(propertyID, req.ChangesVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &models.PropertyModerationDecision{
+		PropertyID:  propertyID,
+		ReviewerID:  reviewerID,
+		Decision:    models.ModerationDecisionRejected,
+		ReasonCodes: codes,
+		Notes:       notes,
+	}
+	subject := fmt.Sprintf("Your listing \"%s\" needs changes before it can be published", property.Title)
+
+	if changes := property.PendingChanges; changes != nil {
+		// the photos waiting for review are deleted along with the decision
+		property.PendingChanges = nil
+		decision.Changes = changes
+		subject = fmt.Sprintf("The changes to your listing \"%s\" were not approved", property.Title)
+	} else {
+		property.Status = models.PropertyStatusRejected
+	}
+
+	if err := s.recordDecision(property, decision, models.PropertyRevisionRejected); err != nil {
+		return nil, err
+	}
+	s.evictProperty(propertyID)
+
+	s.notifyHost(property, decision, subject)
+
+	return property.ToHostResponse(), nil
+}
+
+// saves the decision with its revision, then deletes the objects of the
+// photos it removed. Decisions on a listing that changed since it was read
+// are refused, the moderator has to look at it again.
+func (s *ModerationService) recordDecision(property *models.Property, decision *models.PropertyModerationDecision, action models.PropertyRevisionAction) error {
+	recorded, removed, err := s.moderationRepo.RecordDecision(property, decision, newRevision(decision.ReviewerID, action))
+	if err != nil {
+		logger.Errorf("failed to record moderation decision on property %s: %v", property.ID, err)
+		return err
+	}
+	if !recorded {
+		return errors.New("the listing changed since it was reviewed")
+	}
+
+	for _, image := range removed {
+		s.imageService.deleteObjects(image.ObjectKeys())
+	}
+	return nil
+}
+
+// GetDecisions returns the moderation history of a property, newest first.
+// Hosts see the decisions on their own listings but not who made them.
+func (s *ModerationService) GetDecisions(propertyID, userID uuid.UUID, isAdmin bool) ([]*models.ModerationDecisionResponse, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	if !isAdmin && !s.cohostService.canManage(property, userID, models.CoHostPermissionEditListing) {
+		return nil, errors.New("unauthorized: you can only view your own properties")
+	}
+
+	decisions, err := s.moderationRepo.GetDecisionsByPropertyID(propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation decisions: %w", err)
+	}
+
+	responses := make([]*models.ModerationDecisionResponse, len(decisions))
+	for i, decision := range decisions {
+		responses[i] = decision.ToResponse(isAdmin)
+	}
+
+	return responses, nil
+}
+
+// IsUnknownRejectionReasonError reports whether err is the error
+// RejectProperty returns for a reason code that does not exist
+func IsUnknownRejectionReasonError(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "unknown rejection reason: ")
+}
+
+// a decision changes whether the listing shows up in searches
+func (s *ModerationService) evictProperty(propertyID uuid.UUID) {
+	evictCached(s.caches.Properties, propertyID.String())
+	invalidateCached(s.caches.Search)
+	invalidateCached(s.caches.Suggestions)
+}
+
+// tells the host about a decision. The decision is already saved, so a
+// failure is only logged.
+func (s *ModerationService) notifyHost(property *models.Property, decision *models.PropertyModerationDecision, subject string) {
+	response := decision.ToResponse(false)
+
+	lines := make([]string, 0, len(response.Reasons)+1)
+	for _, reason := range response.Reasons {
+		lines = append(lines, reason.Label)
+	}
+	if response.Notes != "" {
+		lines = append(lines, response.Notes)
+	}
+
+	notificationType := notification.TypeListingApproved
+	if decision.Decision == models.ModerationDecisionRejected {
+		notificationType = notification.TypeListingRejected
+	}
+
+	err := s.notifier.Notify(&notification.Notification{
+		Type:    notificationType,
+		UserID:  property.HostID,
+		Email:   property.Host.Email,
+		Subject: subject,
+		Body:    strings.Join(lines, "\n"),
+		Data: map[string]interface{}{
+			"property_id":  property.ID,
+			"decision_id":  decision.ID,
+			"reason_codes": decision.ReasonCodes,
+		},
+	})
+	if err != nil {
+		logger.Errorf("failed to notify host of moderation decision %s: %v", decision.ID, err)
+	}
+}
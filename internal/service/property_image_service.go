@@ -0,0 +1,386 @@
+package service
+
+import (
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/media"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"airbnb-clone/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// images a single property can have
+	maxPropertyImages = 50
+	// longest image caption
+	maxImageCaptionLength = 500
+	// time allowed to store an image and its variants
+	imageStoreTimeout = 2 * time.Minute
+)
+
+type PropertyImageService struct {
+	imageRepo     repository.PropertyImageRepository
+	propertyRepo  repository.PropertyRepository
+	cohostService *CoHostService
+	store         storage.ObjectStore
+	caches        *Caches
+	maxImageBytes int64
+}
+
+func NewPropertyImageService(imageRepo repository.PropertyImageRepository, propertyRepo repository.PropertyRepository, cohostService *CoHostService, store storage.ObjectStore, caches *Caches, maxImageBytes int64) *PropertyImageService {
+	return &PropertyImageService{
+		imageRepo:     imageRepo,
+		propertyRepo:  propertyRepo,
+		cohostService: cohostService,
+		store:         store,
+		caches:        caches,
+		maxImageBytes: maxImageBytes,
+	}
+}
+
+// MaxImageBytes returns the largest upload accepted
+func (s *PropertyImageService) MaxImageBytes() int64 {
+	return s.maxImageBytes
+}
+
+// the image URLs are part of the cached property and search results
+func (s *PropertyImageService) evictProperty(propertyID uuid.UUID) {
+	evictCached(s.caches.Properties, propertyID.String())
+	invalidateCached(s.caches.Search)
+}
+
+// returns the property if the user hosts it or may edit it as a co-host
+func (s *PropertyImageService) getOwnProperty(propertyID, hostID uuid.UUID) (*models.Property, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	if !s.cohostService.canManage(property, hostID, models.CoHostPermissionEditListing) {
+		return nil, errors.New("unauthorized: you can only update your own properties")
+	}
+
+	return property, nil
+}
+
+// returns the image if it belongs to the property
+func (s *PropertyImageService) getPropertyImage(propertyID, imageID uuid.UUID) (*models.PropertyImage, error) {
+	image, err := s.imageRepo.GetImageByID(imageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("image not found")
+		}
+		return nil, fmt.Errorf("failed to get image: %w", err)
+	}
+
+	if image.PropertyID != propertyID {
+		return nil, errors.New("image not found")
+	}
+
+	return image, nil
+}
+
+// UploadImage checks the upload, stores it with its resized variants and adds
+// it after the existing images of the property. Photos of an approved listing
+// are only shown once a moderator approves them.
+func (s *PropertyImageService) UploadImage(propertyID, hostID uuid.UUID, data []byte, caption string) (*models.PropertyImageResponse, error) {
+	property, err := s.getOwnProperty(propertyID, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	caption = strings.TrimSpace(caption)
+	if len(caption) > maxImageCaptionLength {
+		return nil, errors.New("image caption is too long")
+	}
+	if int64(len(data)) > s.maxImageBytes {
+		return nil, errors.New("image is too large")
+	}
+
+	decoded, err := media.Decode(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupportedType):
+			return nil, errors.New("unsupported image type")
+		case errors.Is(err, media.ErrTooManyPixels):
+			return nil, errors.New("image dimensions are too large")
+		default:
+			return nil, errors.New("invalid image")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), imageStoreTimeout)
+	defer cancel()
+
+	base := fmt.Sprintf("properties/%s/%s", propertyID, uuid.New())
+	image := &models.PropertyImage{
+		PropertyID:    propertyID,
+		Key:           base + decoded.Extension,
+		ContentType:   decoded.ContentType,
+		Size:          int64(len(data)),
+		Width:         decoded.Width,
+		Height:        decoded.Height,
+		Caption:       caption,
+		PendingReview: property.ApprovedAt != nil,
+	}
+	image.URL = s.store.URL(image.Key)
+
+	stored := []string{}
+	// removes what was stored so far when a later step fails
+	cleanup := func() {
+		s.deleteObjects(stored)
+	}
+
+	if err := s.store.Put(ctx, image.Key, data, decoded.ContentType); err != nil {
+		return nil, fmt.Errorf("failed to store image: %w", err)
+	}
+	stored = append(stored, image.Key)
+
+	for _, variant := range media.Variants {
+		resized, width, height, err := decoded.Resize(variant.Width)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to resize image: %w", err)
+		}
+
+		key := fmt.Sprintf("%s_%s.jpg", base, variant.Name)
+		if err := s.store.Put(ctx, key, resized, "image/jpeg"); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to store image: %w", err)
+		}
+		stored = append(stored, key)
+
+		image.Variants = append(image.Variants, models.ImageVariant{
+			Name:   variant.Name,
+			Key:    key,
+			URL:    s.store.URL(key),
+			Width:  width,
+			Height: height,
+		})
+	}
+
+	// the limit is checked under the lock of the property, parallel uploads
+	// could pass it otherwise
+	added, err := s.imageRepo.AddImage(image, maxPropertyImages, newRevision(hostID, models.PropertyRevisionUpdated))
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to save image: %w", err)
+	}
+	if !added {
+		cleanup()
+		return nil, errors.New("property has too many images")
+	}
+
+	s.evictProperty(propertyID)
+	return image.ToResponse(), nil
+}
+
+// GetPropertyImages returns the images of a property, cover first. Images
+// waiting for review are only included for the host and admins; viewerID is
+// uuid.Nil for guests who are not signed in.
+func (s *PropertyImageService) GetPropertyImages(propertyID, viewerID uuid.UUID, isAdmin bool) ([]*models.PropertyImageResponse, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	includePending := isAdmin || (viewerID != uuid.Nil && s.cohostService.canManage(property, viewerID, models.CoHostPermissionEditListing))
+	images, err := s.imageRepo.GetImagesByPropertyID(propertyID, includePending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images: %w", err)
+	}
+
+	responses := make([]*models.PropertyImageResponse, len(images))
+	for i, image := range images {
+		responses[i] = image.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// UpdateImage changes the caption of an image or makes it the cover
+func (s *PropertyImageService) UpdateImage(propertyID, imageID, hostID uuid.UUID, req *models.PropertyImageUpdateRequest) (*models.PropertyImageResponse, error) {
+	if _, err := s.getOwnProperty(propertyID, hostID); err != nil {
+		return nil, err
+	}
+
+	image, err := s.getPropertyImage(propertyID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IsCover != nil && !*req.IsCover {
+		return nil, errors.New("set another image as cover instead")
+	}
+	if req.IsCover != nil && image.PendingReview {
+		return nil, errors.New("image is waiting for review")
+	}
+
+	if req.Caption != nil {
+		caption := strings.TrimSpace(*req.Caption)
+		if len(caption) > maxImageCaptionLength {
+			return nil, errors.New("image caption is too long")
+		}
+		image.Caption = caption
+
+		if err := s.imageRepo.UpdateImage(image); err != nil {
+			return nil, fmt.Errorf("failed to update image: %w", err)
+		}
+	}
+
+	if req.IsCover != nil && !image.IsCover {
+		if err := s.imageRepo.SetCover(propertyID, imageID, newRevision(hostID, models.PropertyRevisionUpdated)); err != nil {
+			return nil, fmt.Errorf("failed to set cover image: %w", err)
+		}
+		image.IsCover = true
+		s.evictProperty(propertyID)
+	}
+
+	return image.ToResponse(), nil
+}
+
+// ReorderImages puts the images in the order of imageIDs, which has to list
+// every image of the property exactly once. The cover always stays first.
+func (s *PropertyImageService) ReorderImages(propertyID, hostID uuid.UUID, imageIDs []uuid.UUID) ([]*models.PropertyImageResponse, error) {
+	if _, err := s.getOwnProperty(propertyID, hostID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.imageRepo.GetImagesByPropertyID(propertyID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images: %w", err)
+	}
+
+	remaining := make(map[uuid.UUID]bool, len(images))
+	for _, image := range images {
+		remaining[image.ID] = true
+	}
+	for _, id := range imageIDs {
+		if !remaining[id] {
+			return nil, errors.New("image order must list every image of the property exactly once")
+		}
+		delete(remaining, id)
+	}
+	if len(remaining) > 0 {
+		return nil, errors.New("image order must list every image of the property exactly once")
+	}
+
+	if err := s.imageRepo.ReorderImages(propertyID, imageIDs, newRevision(hostID, models.PropertyRevisionUpdated)); err != nil {
+		return nil, fmt.Errorf("failed to reorder images: %w", err)
+	}
+
+	s.evictProperty(propertyID)
+	return s.GetPropertyImages(propertyID, hostID, false)
+}
+
+// DeleteImage removes an image and its stored objects
+func (s *PropertyImageService) DeleteImage(propertyID, imageID, hostID uuid.UUID) error {
+	if _, err := s.getOwnProperty(propertyID, hostID); err != nil {
+		return err
+	}
+
+	image, err := s.getPropertyImage(propertyID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.imageRepo.DeleteImage(image, newRevision(hostID, models.PropertyRevisionUpdated)); err != nil {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+
+	s.deleteObjects(image.ObjectKeys())
+	s.evictProperty(propertyID)
+	return nil
+}
+
+// replaceImages makes the images of the property match urls, as set through
+// Property.Images on create and update. Uploaded images that are no longer
+// listed are deleted along with their objects. The revision, if any, is
+// recorded along with the images.
+func (s *PropertyImageService) replaceImages(propertyID uuid.UUID, urls []string, revision *models.PropertyRevision) error {
+	unique, err := normalizeImageURLs(urls)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.imageRepo.ReplaceImages(propertyID, unique, revision)
+	if err != nil {
+		return fmt.Errorf("failed to update images: %w", err)
+	}
+
+	for _, image := range removed {
+		s.deleteObjects(image.ObjectKeys())
+	}
+	return nil
+}
+
+// trims image URLs and drops blank and repeated ones
+func normalizeImageURLs(urls []string) ([]string, error) {
+	unique := make([]string, 0, len(urls))
+	seen := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		url = strings.TrimSpace(url)
+		if url != "" && !seen[url] {
+			seen[url] = true
+			unique = append(unique, url)
+		}
+	}
+	if len(unique) > maxPropertyImages {
+		return nil, errors.New("property has too many images")
+	}
+	return unique, nil
+}
+
+// restorableImages drops the uploads from urls that were deleted since, their
+// files are gone with them. URLs added as links are kept.
+func (s *PropertyImageService) restorableImages(propertyID uuid.UUID, urls []string) ([]string, error) {
+	images, err := s.imageRepo.GetImagesByPropertyID(propertyID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images: %w", err)
+	}
+	existing := make(map[string]bool, len(images))
+	for _, image := range images {
+		existing[image.URL] = true
+	}
+
+	uploadPrefix := s.store.URL(fmt.Sprintf("properties/%s/", propertyID))
+	restorable := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if existing[url] || !strings.HasPrefix(url, uploadPrefix) {
+			restorable = append(restorable, url)
+		}
+	}
+	return restorable, nil
+}
+
+// removes stored objects. Failures only leave unreferenced files behind, so
+// they are logged rather than returned.
+func (s *PropertyImageService) deleteObjects(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), imageStoreTimeout)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			logger.Errorf("failed to delete stored image %s: %v", key, err)
+		}
+	}
+}
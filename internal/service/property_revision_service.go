@@ -0,0 +1,125 @@
+package service
+
+import (
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PropertyRevisionService struct {
+	revisionRepo  repository.PropertyRevisionRepository
+	propertyRepo  repository.PropertyRepository
+	cohostService *CoHostService
+}
+
+func NewPropertyRevisionService(revisionRepo repository.PropertyRevisionRepository, propertyRepo repository.PropertyRepository, cohostService *CoHostService) *PropertyRevisionService {
+	return &PropertyRevisionService{
+		revisionRepo:  revisionRepo,
+		propertyRepo:  propertyRepo,
+		cohostService: cohostService,
+	}
+}
+
+// newRevision describes the revision a change records. The repository saves
+// it in the transaction of the change, from the row as written, so a
+// booking never links a revision older than the listing it saw.
+func newRevision(actorID uuid.UUID, action models.PropertyRevisionAction) *models.PropertyRevision {
+	return &models.PropertyRevision{
+		Action:  action,
+		ActorID: actorID,
+	}
+}
+
+// returns the property if the user is its host, a co-host who may edit it or
+// an admin
+func (s *PropertyRevisionService) getViewableProperty(propertyID, userID uuid.UUID, isAdmin bool) (*models.Property, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	if !isAdmin && !s.cohostService.canManage(property, userID, models.CoHostPermissionEditListing) {
+		return nil, errors.New("unauthorized: you can only view your own properties")
+	}
+
+	return property, nil
+}
+
+// returns the revision if it belongs to the property
+func (s *PropertyRevisionService) getRevision(propertyID, revisionID uuid.UUID) (*models.PropertyRevision, error) {
+	revision, err := s.revisionRepo.GetRevisionByID(revisionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("revision not found")
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	if revision.PropertyID != propertyID {
+		return nil, errors.New("revision not found")
+	}
+
+	return revision, nil
+}
+
+// GetRevisions returns the revisions of a listing to its host or an admin,
+// newest first
+func (s *PropertyRevisionService) GetRevisions(propertyID, userID uuid.UUID, isAdmin bool, page, limit int, cursor string) ([]*models.PropertyRevisionResponse, string, error) {
+	if _, err := s.getViewableProperty(propertyID, userID, isAdmin); err != nil {
+		return nil, "", err
+	}
+
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	revisions, err := s.revisionRepo.GetRevisionsByPropertyID(propertyID, pageQuery)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get revisions: %w", err)
+	}
+	revisions, nextCursor := models.TrimPage(revisions, pageQuery, (*models.PropertyRevision).PageCursor)
+
+	responses := make([]*models.PropertyRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = revision.ToResponse()
+	}
+
+	return responses, nextCursor, nil
+}
+
+// GetRevision returns one revision of a listing to its host or an admin
+func (s *PropertyRevisionService) GetRevision(propertyID, revisionID, userID uuid.UUID, isAdmin bool) (*models.PropertyRevisionResponse, error) {
+	if _, err := s.getViewableProperty(propertyID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.getRevision(propertyID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return revision.ToResponse(), nil
+}
+
+// latestRevisionID returns the revision that is live, or nil when the
+// property has none
+func (s *PropertyRevisionService) latestRevisionID(propertyID uuid.UUID) *uuid.UUID {
+	revision, err := s.revisionRepo.GetLatestRevision(propertyID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Errorf("failed to get latest revision of property %s: %v", propertyID, err)
+		}
+		return nil
+	}
+	return &revision.ID
+}
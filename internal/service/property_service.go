@@ -4,59 +4,120 @@ import (
 	"airbnb-clone/internal/cache"
 	"airbnb-clone/internal/logger"
 	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/recommend"
 	"airbnb-clone/internal/repository"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 type PropertyService struct {
-	propertyRepo repository.PropertyRepository
-	redisClient  *cache.RedisClient
+	propertyRepo    repository.PropertyRepository
+	amenityService  *AmenityService
+	imageService    *PropertyImageService
+	revisionService *PropertyRevisionService
+	cohostService   *CoHostService
+	caches          *Caches
 }
 
-func NewPropertyService(propertyRepo repository.PropertyRepository, redisClient *cache.RedisClient) *PropertyService {
+func NewPropertyService(propertyRepo repository.PropertyRepository, amenityService *AmenityService, imageService *PropertyImageService, revisionService *PropertyRevisionService, cohostService *CoHostService, caches *Caches) *PropertyService {
 	return &PropertyService{
-		propertyRepo: propertyRepo,
-		redisClient:  redisClient,
+		propertyRepo:    propertyRepo,
+		amenityService:  amenityService,
+		imageService:    imageService,
+		revisionService: revisionService,
+		cohostService:   cohostService,
+		caches:          caches,
 	}
 }
 
+// drops the cached copy of a property and every cached search page and
+// destination suggestion, which may count it
+func (s *PropertyService) evictProperty(propertyID uuid.UUID) {
+	evictCached(s.caches.Properties, propertyID.String())
+	invalidateCached(s.caches.Search)
+	invalidateCached(s.caches.Suggestions)
+}
+
 func (s *PropertyService) CreateProperty(hostID uuid.UUID, req *models.PropertyCreateRequest) (*models.PropertyResponse, error) {
+	if len(req.Images) > maxPropertyImages {
+		return nil, errors.New("property has too many images")
+	}
+
+	amenities, err := s.amenityService.ResolveAmenities(req.Amenities)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := normalizeRooms(req.Rooms)
+	if err != nil {
+		return nil, err
+	}
+
+	houseRules, err := normalizeHouseRules(req.HouseRules)
+	if err != nil {
+		return nil, err
+	}
+
+	accessibility, err := normalizeAccessibilityFeatures(req.AccessibilityFeatures)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := normalizeImageURLs(req.Images)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create property
 	property := &models.Property{
-		HostID:        hostID,
-		Title:         req.Title,
-		Description:   req.Description,
-		Type:          req.Type,
-		Status:        models.PropertyStatusPending, // Default to pending for approval
-		PricePerNight: req.PricePerNight,
-		Currency:      req.Currency,
-		MaxGuests:     req.MaxGuests,
-		Bedrooms:      req.Bedrooms,
-		Bathrooms:     req.Bathrooms,
-		Address:       req.Address,
-		City:          req.City,
-		State:         req.State,
-		Country:       req.Country,
-		ZipCode:       req.ZipCode,
-		Latitude:      req.Latitude,
-		Longitude:     req.Longitude,
-		Amenities:     req.Amenities,
-		Images:        req.Images,
-		Rules:         req.Rules,
-		CheckInTime:   req.CheckInTime,
-		CheckOutTime:  req.CheckOutTime,
+		HostID:                hostID,
+		Title:                 req.Title,
+		Description:           req.Description,
+		Type:                  req.Type,
+		Status:                models.PropertyStatusPending, // Default to pending for approval
+		PricePerNight:         req.PricePerNight,
+		Currency:              req.Currency,
+		MaxGuests:             req.MaxGuests,
+		Bedrooms:              req.Bedrooms,
+		Bathrooms:             req.Bathrooms,
+		Rooms:                 rooms,
+		TotalBeds:             rooms.TotalBeds(),
+		Address:               req.Address,
+		City:                  req.City,
+		State:                 req.State,
+		Country:               req.Country,
+		ZipCode:               req.ZipCode,
+		Latitude:              req.Latitude,
+		Longitude:             req.Longitude,
+		Amenities:             amenities,
+		Images:                images,
+		Rules:                 req.Rules,
+		HouseRules:            houseRules,
+		AccessibilityFeatures: accessibility,
+		AccessibilityNotes:    strings.TrimSpace(req.AccessibilityNotes),
+		CheckInTime:           req.CheckInTime,
+		CheckOutTime:          req.CheckOutTime,
 	}
 
 	if property.Currency == "" {
 		property.Currency = "USD"
 	}
+	submittedAt := time.Now()
+	property.SubmittedAt = &submittedAt
+	// the listed rooms are the more detailed source
+	if len(rooms) > 0 {
+		property.Bedrooms = rooms.Bedrooms()
+	}
 
-	err := s.propertyRepo.Create(property)
+	// image URLs given up front are kept as images that were not uploaded
+	err = s.propertyRepo.Create(property, newRevision(hostID, models.PropertyRevisionCreated))
 	if err != nil {
 		logger.Errorf("failed to create property: %v", err)
 		return nil, err
@@ -73,42 +134,130 @@ func (s *PropertyService) CreateProperty(hostID uuid.UUID, req *models.PropertyC
 	return createdProperty.ToResponse(), nil
 }
 
-func (s *PropertyService) GetProperty(propertyID uuid.UUID) (*models.PropertyResponse, error) {
-	cacheKey := fmt.Sprintf("property:%s", propertyID.String())
+const (
+	// rooms a single property can list
+	maxPropertyRooms = 30
+	// beds of one type and size in a room
+	maxBedsPerEntry = 10
+)
 
-	// Try Redis first
-	cached, err := s.redisClient.Get(cacheKey)
-	if err == nil {
-		var property models.Property
-		err = json.Unmarshal([]byte(cached), &property)
-		if err == nil {
-			// Cache hit — return cached property
-			return property.ToResponse(), nil
-		}
-		logger.Warnf("failed to unmarshal cached property: %v", err)
+// checks the rooms of a listing and trims their names
+func normalizeRooms(rooms []models.PropertyRoom) (models.PropertyRooms, error) {
+	if len(rooms) > maxPropertyRooms {
+		return nil, errors.New("property has too many rooms")
 	}
 
-	property, err := s.propertyRepo.GetPropertyByID(propertyID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("property not found")
+	normalized := make(models.PropertyRooms, len(rooms))
+	for i, room := range rooms {
+		switch room.Type {
+		case models.RoomTypeBedroom, models.RoomTypeLivingRoom, models.RoomTypeCommonSpace, models.RoomTypeOther:
+		default:
+			return nil, errors.New("invalid room type")
 		}
-		logger.Errorf("failed to get property: %v", err)
-		return nil, err
+
+		room.Name = strings.TrimSpace(room.Name)
+		if len(room.Name) > 50 {
+			return nil, errors.New("room name is too long")
+		}
+
+		beds := make([]models.Bed, len(room.Beds))
+		for j, bed := range room.Beds {
+			switch bed.Type {
+			case models.BedTypeBed, models.BedTypeSofaBed, models.BedTypeBunkBed, models.BedTypeFloorMattress, models.BedTypeCrib:
+			default:
+				return nil, errors.New("invalid bed type")
+			}
+
+			switch bed.Size {
+			case "":
+				if bed.Type == models.BedTypeBed || bed.Type == models.BedTypeSofaBed {
+					return nil, errors.New("bed size is required for beds and sofa beds")
+				}
+			case models.BedSizeSingle, models.BedSizeDouble, models.BedSizeQueen, models.BedSizeKing:
+			default:
+				return nil, errors.New("invalid bed size")
+			}
+
+			if bed.Count < 1 || bed.Count > maxBedsPerEntry {
+				return nil, errors.New("bed count must be between 1 and 10")
+			}
+			beds[j] = bed
+		}
+		room.Beds = beds
+
+		normalized[i] = room
 	}
 
-	// Cache the property in Redis
-	propertyJSON, err := json.Marshal(property)
-	if err != nil {
-		logger.Errorf("failed to marshal property for caching: %v", err)
-	} else {
-		err = s.redisClient.Set(cacheKey, string(propertyJSON), 0) // No expiration
+	return normalized, nil
+}
+
+// checks that quiet hours are times of day given together
+func normalizeHouseRules(rules models.HouseRules) (models.HouseRules, error) {
+	rules.QuietHoursStart = strings.TrimSpace(rules.QuietHoursStart)
+	rules.QuietHoursEnd = strings.TrimSpace(rules.QuietHoursEnd)
+	if (rules.QuietHoursStart == "") != (rules.QuietHoursEnd == "") {
+		return rules, errors.New("quiet hours need both a start and an end")
+	}
+
+	for _, value := range []*string{&rules.QuietHoursStart, &rules.QuietHoursEnd} {
+		if *value == "" {
+			continue
+		}
+		t, err := time.Parse(models.QuietHoursFormat, *value)
 		if err != nil {
-			logger.Errorf("failed to cache property: %v", err)
+			return rules, errors.New("invalid quiet hours. Use HH:MM")
 		}
+		*value = t.Format(models.QuietHoursFormat)
+	}
+	if rules.QuietHoursStart != "" && rules.QuietHoursStart == rules.QuietHoursEnd {
+		return rules, errors.New("quiet hours must start and end at different times")
 	}
 
-	return property.ToResponse(), nil
+	return rules, nil
+}
+
+// checks accessibility features against the supported ones and drops
+// duplicates
+func normalizeAccessibilityFeatures(features []string) (pq.StringArray, error) {
+	normalized := make(pq.StringArray, 0, len(features))
+	seen := make(map[string]bool, len(features))
+	for _, feature := range features {
+		feature = strings.ToLower(strings.TrimSpace(feature))
+		if feature == "" || seen[feature] {
+			continue
+		}
+		if !models.IsValidAccessibilityFeature(models.AccessibilityFeature(feature)) {
+			return nil, fmt.Errorf("unknown accessibility feature: %s", feature)
+		}
+		seen[feature] = true
+		normalized = append(normalized, feature)
+	}
+	return normalized, nil
+}
+
+// IsUnknownAccessibilityFeatureError reports whether err was returned for an
+// accessibility feature that is not supported
+func IsUnknownAccessibilityFeatureError(err error) bool {
+	return strings.HasPrefix(err.Error(), "unknown accessibility feature: ")
+}
+
+func (s *PropertyService) GetProperty(propertyID uuid.UUID) (*models.PropertyResponse, error) {
+	return cache.GetOrLoad(s.caches.Properties, propertyID.String(), func() (*models.PropertyResponse, error) {
+		property, err := s.propertyRepo.GetPropertyByID(propertyID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("property not found")
+			}
+			logger.Errorf("failed to get property: %v", err)
+			return nil, err
+		}
+		// drafts are only shown to their host, see GetDraft
+		if property.Status == models.PropertyStatusDraft {
+			return nil, errors.New("property not found")
+		}
+
+		return property.ToResponse(), nil
+	})
 }
 
 func (s *PropertyService) UpdateProperty(propertyID, hostID uuid.UUID, req *models.PropertyUpdateRequest) (*models.PropertyResponse, error) {
@@ -121,11 +270,36 @@ func (s *PropertyService) UpdateProperty(propertyID, hostID uuid.UUID, req *mode
 		return nil, err
 	}
 
-	// check if user is the host of this property
-	if property.HostID != hostID {
+	// check if user is the host of this property or may edit it for them
+	if !s.cohostService.canManage(property, hostID, models.CoHostPermissionEditListing) {
 		return nil, errors.New("unauthorized: you can only update your own properties")
 	}
 
+	if len(req.Images) > maxPropertyImages {
+		return nil, errors.New("property has too many images")
+	}
+
+	// hosts can take an approved listing offline and back; every other status
+	// is set through submitting and moderation
+	if req.Status != "" && req.Status != property.Status {
+		if req.Status != models.PropertyStatusActive && req.Status != models.PropertyStatusInactive {
+			return nil, errors.New("hosts can only set the status to active or inactive")
+		}
+		if property.ApprovedAt == nil {
+			return nil, errors.New("only approved listings can be activated or deactivated")
+		}
+	}
+
+	rooms, err := normalizeRooms(req.Rooms)
+	if err != nil {
+		return nil, err
+	}
+
+	accessibility, err := normalizeAccessibilityFeatures(req.AccessibilityFeatures)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update fields if provided
 	if req.Title != "" {
 		property.Title = req.Title
@@ -133,9 +307,6 @@ func (s *PropertyService) UpdateProperty(propertyID, hostID uuid.UUID, req *mode
 	if req.Description != "" {
 		property.Description = req.Description
 	}
-	if req.Type != "" {
-		property.Type = req.Type
-	}
 	if req.Status != "" {
 		property.Status = req.Status
 	}
@@ -154,35 +325,35 @@ func (s *PropertyService) UpdateProperty(propertyID, hostID uuid.UUID, req *mode
 	if req.Bathrooms > 0 {
 		property.Bathrooms = req.Bathrooms
 	}
-	if req.Address != "" {
-		property.Address = req.Address
-	}
-	if req.City != "" {
-		property.City = req.City
-	}
-	if req.State != "" {
-		property.State = req.State
-	}
-	if req.Country != "" {
-		property.Country = req.Country
-	}
-	if req.ZipCode != "" {
-		property.ZipCode = req.ZipCode
+	if req.Amenities != nil {
+		amenities, err := s.amenityService.ResolveAmenities(req.Amenities)
+		if err != nil {
+			return nil, err
+		}
+		property.Amenities = amenities
 	}
-	if req.Latitude != 0 {
-		property.Latitude = req.Latitude
+	if req.Rules != nil {
+		property.Rules = req.Rules
 	}
-	if req.Longitude != 0 {
-		property.Longitude = req.Longitude
+	if req.HouseRules != nil {
+		houseRules, err := normalizeHouseRules(*req.HouseRules)
+		if err != nil {
+			return nil, err
+		}
+		property.HouseRules = houseRules
 	}
-	if req.Amenities != nil {
-		property.Amenities = req.Amenities
+	if req.AccessibilityFeatures != nil {
+		property.AccessibilityFeatures = accessibility
 	}
-	if req.Images != nil {
-		property.Images = req.Images
+	if req.AccessibilityNotes != nil {
+		property.AccessibilityNotes = strings.TrimSpace(*req.AccessibilityNotes)
 	}
-	if req.Rules != nil {
-		property.Rules = req.Rules
+	if req.Rooms != nil {
+		property.Rooms = rooms
+		property.TotalBeds = rooms.TotalBeds()
+		if len(rooms) > 0 {
+			property.Bedrooms = rooms.Bedrooms()
+		}
 	}
 	if !req.CheckInTime.IsZero() {
 		property.CheckInTime = req.CheckInTime
@@ -191,13 +362,133 @@ func (s *PropertyService) UpdateProperty(propertyID, hostID uuid.UUID, req *mode
 		property.CheckOutTime = req.CheckOutTime
 	}
 
-	err = s.propertyRepo.UpdateProperty(property)
+	property, err = s.saveWithChanges(property, materialChanges(req), newRevision(hostID, models.PropertyRevisionUpdated))
+	if err != nil {
+		return nil, err
+	}
+	s.evictProperty(propertyID)
+
+	return property.ToHostResponse(), nil
+}
+
+// saveWithChanges saves the property along with the material changes. Those
+// to an approved listing wait for a moderator while the listing stays live as
+// it was approved; they are applied right away to listings never approved.
+// The revision is recorded with the last write.
+func (s *PropertyService) saveWithChanges(property *models.Property, changes *models.PropertyChanges, revision *models.PropertyRevision) (*models.Property, error) {
+	review := property.ApprovedAt != nil
+	var pending *models.PropertyChanges
+	if review {
+		changes = changes.Without(property)
+		if !changes.IsEmpty() {
+			now := time.Now()
+			changes.RequestedAt = &now
+			pending = changes
+		}
+	} else {
+		changes.ApplyTo(property)
+	}
+
+	// a new list of image URLs replaces the images, uploaded ones included
+	replaceImages := changes.Images != nil && !review
+	updateRevision := revision
+	if replaceImages {
+		updateRevision = nil
+	}
+
+	updated, err := s.propertyRepo.UpdateProperty(property, pending, updateRevision)
 	if err != nil {
 		logger.Errorf("failed to update property: %v", err)
 		return nil, err
 	}
+	if !updated {
+		return nil, errors.New("the listing changed meanwhile, reload it and try again")
+	}
 
-	return property.ToResponse(), nil
+	if replaceImages {
+		if err := s.imageService.replaceImages(property.ID, changes.Images, revision); err != nil {
+			logger.Errorf("failed to update property images: %v", err)
+			return nil, err
+		}
+
+		property, err = s.propertyRepo.GetPropertyByID(property.ID)
+		if err != nil {
+			logger.Errorf("failed to fetch updated property: %v", err)
+			return nil, err
+		}
+	}
+
+	return property, nil
+}
+
+// RollbackProperty restores a listing of the host to an earlier revision.
+// The status is left as it is, and material fields of an approved listing go
+// to a moderator like any other change to them. Uploaded photos deleted since
+// cannot be brought back.
+func (s *PropertyService) RollbackProperty(propertyID, revisionID, hostID uuid.UUID) (*models.PropertyResponse, error) {
+	property, err := s.getOwnProperty(propertyID, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revisionService.getRevision(propertyID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := revision.Snapshot
+
+	// amenities retired from the catalog since are rejected like on update
+	snapshot.Amenities, err = s.amenityService.ResolveAmenities(snapshot.Amenities)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Images, err = s.imageService.restorableImages(propertyID, snapshot.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.RestoreTo(property)
+	property, err = s.saveWithChanges(property, snapshot.Changes(), newRevision(hostID, models.PropertyRevisionRolledBack))
+	if err != nil {
+		return nil, err
+	}
+	s.evictProperty(propertyID)
+
+	return property.ToHostResponse(), nil
+}
+
+// collects the fields of the update that an approved listing needs a
+// moderator to look at again
+func materialChanges(req *models.PropertyUpdateRequest) *models.PropertyChanges {
+	changes := &models.PropertyChanges{}
+	if req.Type != "" {
+		changes.Type = &req.Type
+	}
+	if req.Address != "" {
+		changes.Address = &req.Address
+	}
+	if req.City != "" {
+		changes.City = &req.City
+	}
+	if req.State != "" {
+		changes.State = &req.State
+	}
+	if req.Country != "" {
+		changes.Country = &req.Country
+	}
+	if req.ZipCode != "" {
+		changes.ZipCode = &req.ZipCode
+	}
+	if req.Latitude != 0 {
+		changes.Latitude = &req.Latitude
+	}
+	if req.Longitude != 0 {
+		changes.Longitude = &req.Longitude
+	}
+	if req.Images != nil {
+		changes.Images = req.Images
+	}
+	return changes
 }
 
 func (s *PropertyService) DeleteProperty(propertyID, hostID uuid.UUID) error {
@@ -220,82 +511,220 @@ func (s *PropertyService) DeleteProperty(propertyID, hostID uuid.UUID) error {
 		logger.Errorf("failed to delete property: %v", err)
 		return err
 	}
+	s.evictProperty(propertyID)
 
 	return nil
 }
 
-func (s *PropertyService) GetProperties(page, limit int) ([]*models.PropertyResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
+func (s *PropertyService) GetProperties(page, limit int, cursor string) ([]*models.PropertyResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
 	}
-	offset := (page - 1) * limit
 
-	properties, err := s.propertyRepo.ListProperties(offset, limit)
+	properties, err := s.propertyRepo.ListProperties(pageQuery)
 	if err != nil {
 		logger.Errorf("failed to get properties: %v", err)
-		return nil, err
+		return nil, "", err
 	}
+	properties, nextCursor := models.TrimPage(properties, pageQuery, (*models.Property).PageCursor)
 
 	responses := make([]*models.PropertyResponse, len(properties))
 	for i, property := range properties {
 		responses[i] = property.ToResponse()
 	}
 
-	return responses, nil
+	return responses, nextCursor, nil
 }
 
 func (s *PropertyService) SearchProperties(req *models.PropertySearchRequest) (*models.PropertySearchResponse, error) {
-	properties, total, err := s.propertyRepo.SearchProperties(req)
+	// surrounding whitespace never changes the results, so drop it before
+	// the request is used as a cache key
+	req.Query = strings.TrimSpace(req.Query)
+	req.City = strings.TrimSpace(req.City)
+	req.State = strings.TrimSpace(req.State)
+	req.Country = strings.TrimSpace(req.Country)
+	req.Type = strings.TrimSpace(req.Type)
+
+	// filters match on catalog ids, whatever spelling was asked for
+	amenities, err := s.amenityService.ResolveAmenities(req.Amenities)
 	if err != nil {
-		logger.Errorf("failed to search properties: %v", err)
 		return nil, err
 	}
+	req.Amenities = amenities
 
-	// Convert to response format
-	responses := make([]*models.PropertyResponse, len(properties))
-	for i, property := range properties {
-		responses[i] = property.ToResponse()
+	accessibility, err := normalizeAccessibilityFeatures(req.Accessibility)
+	if err != nil {
+		return nil, err
 	}
+	req.Accessibility = accessibility
+
+	return cache.GetOrLoad(s.caches.Search, cache.SearchKey(req), func() (*models.PropertySearchResponse, error) {
+		properties, total, err := s.propertyRepo.SearchProperties(req)
+		if err != nil {
+			logger.Errorf("failed to search properties: %v", err)
+			return nil, err
+		}
 
-	// Calculate total pages
-	totalPages := int(total) / req.Limit
-	if int(total)%req.Limit != 0 {
-		totalPages++
+		facets, err := s.propertyRepo.SearchFacets(req)
+		if err != nil {
+			logger.Errorf("failed to compute search facets: %v", err)
+			return nil, err
+		}
+
+		// Convert to response format
+		responses := make([]*models.PropertyResponse, len(properties))
+		for i, property := range properties {
+			responses[i] = property.ToResponse()
+		}
+
+		// Calculate total pages
+		totalPages := int(total) / req.Limit
+		if int(total)%req.Limit != 0 {
+			totalPages++
+		}
+
+		return &models.PropertySearchResponse{
+			Properties: responses,
+			Total:      total,
+			Page:       req.Page,
+			Limit:      req.Limit,
+			TotalPages: totalPages,
+			Facets:     facets,
+		}, nil
+	})
+}
+
+func (s *PropertyService) SuggestDestinations(query string, limit int) ([]*models.DestinationSuggestion, error) {
+	prefix := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if prefix == "" {
+		return nil, errors.New("search query is required")
+	}
+	if limit <= 0 || limit > maxDestinationSuggestions {
+		limit = defaultDestinationSuggestions
 	}
 
-	return &models.PropertySearchResponse{
-		Properties: responses,
-		Total:      total,
-		Page:       req.Page,
-		Limit:      req.Limit,
-		TotalPages: totalPages,
-	}, nil
+	key := fmt.Sprintf("%d:%s", limit, prefix)
+	return cache.GetOrLoad(s.caches.Suggestions, key, func() ([]*models.DestinationSuggestion, error) {
+		suggestions, err := s.propertyRepo.SuggestDestinations(prefix, limit)
+		if err != nil {
+			logger.Errorf("failed to suggest destinations: %v", err)
+			return nil, err
+		}
+
+		for _, suggestion := range suggestions {
+			suggestion.Label = destinationLabel(suggestion)
+		}
+
+		return suggestions, nil
+	})
+}
+
+const (
+	defaultDestinationSuggestions = 10
+	maxDestinationSuggestions     = 20
+)
+
+// joins the non-empty parts of a destination, e.g. "Austin, Texas, United States"
+func destinationLabel(suggestion *models.DestinationSuggestion) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{suggestion.City, suggestion.State, suggestion.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
-func (s *PropertyService) GetPropertiesByHost(hostID uuid.UUID, page, limit int) ([]*models.PropertyResponse, error) {
-	if page <= 0 {
-		page = 1
+// GetSimilarProperties returns active listings near the property that look
+// most like it, best match first. When checkIn and checkOut are set, listings
+// that are booked for those dates are left out.
+func (s *PropertyService) GetSimilarProperties(propertyID uuid.UUID, checkIn, checkOut time.Time, limit int) ([]*models.PropertyResponse, error) {
+	if limit <= 0 || limit > maxSimilarProperties {
+		limit = defaultSimilarProperties
 	}
-	if limit <= 0 {
-		limit = 20
+	if !checkIn.IsZero() && !checkOut.After(checkIn) {
+		return nil, errors.New("check-out date must be after check-in date")
 	}
-	offset := (page - 1) * limit
 
-	properties, err := s.propertyRepo.GetPropertiesByHostID(hostID, offset, limit)
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
 	if err != nil {
-		logger.Errorf("failed to get properties by host: %v", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	candidates, err := s.propertyRepo.GetSimilarCandidates(property, recommend.DefaultOptions.MaxDistanceKm, checkIn, checkOut, similarCandidatePool)
+	if err != nil {
+		logger.Errorf("failed to get similar property candidates: %v", err)
 		return nil, err
 	}
 
+	byID := make(map[uuid.UUID]*models.Property, len(candidates))
+	listings := make([]recommend.Listing, len(candidates))
+	for i, candidate := range candidates {
+		byID[candidate.ID] = candidate
+		listings[i] = toListing(candidate)
+	}
+
+	matches := recommend.Rank(toListing(property), listings, recommend.DefaultOptions, limit)
+
+	responses := make([]*models.PropertyResponse, len(matches))
+	for i, match := range matches {
+		response := byID[match.Listing.ID].ToResponse()
+		score := match.Score
+		distance := match.DistanceKm
+		response.SimilarityScore = &score
+		response.DistanceKm = &distance
+		responses[i] = response
+	}
+
+	return responses, nil
+}
+
+const (
+	defaultSimilarProperties = 10
+	maxSimilarProperties     = 30
+	// nearest listings that are scored for each recommendation
+	similarCandidatePool = 200
+)
+
+func toListing(property *models.Property) recommend.Listing {
+	return recommend.Listing{
+		ID:            property.ID,
+		Type:          string(property.Type),
+		PricePerNight: property.PricePerNight,
+		MaxGuests:     property.MaxGuests,
+		Bedrooms:      property.Bedrooms,
+		Amenities:     property.Amenities,
+		Latitude:      property.Latitude,
+		Longitude:     property.Longitude,
+		AverageRating: property.AverageRating,
+		ReviewCount:   property.ReviewCount,
+	}
+}
+
+func (s *PropertyService) GetPropertiesByHost(hostID uuid.UUID, page, limit int, cursor string) ([]*models.PropertyResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	properties, err := s.propertyRepo.GetPropertiesByHostID(hostID, pageQuery)
+	if err != nil {
+		logger.Errorf("failed to get properties by host: %v", err)
+		return nil, "", err
+	}
+	properties, nextCursor := models.TrimPage(properties, pageQuery, (*models.Property).PageCursor)
+
 	responses := make([]*models.PropertyResponse, len(properties))
 	for i, property := range properties {
 		responses[i] = property.ToResponse()
 	}
 
-	return responses, nil
+	return responses, nextCursor, nil
 }
 
 func (s *PropertyService) CheckAvailability(propertyID uuid.UUID, checkIn, checkOut string) (bool, error) {
@@ -323,7 +752,29 @@ func (s *PropertyService) CheckAvailability(propertyID uuid.UUID, checkIn, check
 	return available, nil
 }
 
-func (s *PropertyService) ApproveProperty(propertyID uuid.UUID) (*models.PropertyResponse, error) {
+// CreateDraft saves a listing the host fills in step by step. Nothing is
+// required until the draft is submitted.
+func (s *PropertyService) CreateDraft(hostID uuid.UUID, req *models.DraftBasicsRequest) (*models.PropertyDraftResponse, error) {
+	property := &models.Property{
+		HostID:   hostID,
+		Status:   models.PropertyStatusDraft,
+		Currency: "USD",
+	}
+	if err := applyDraftBasics(property, req); err != nil {
+		return nil, err
+	}
+
+	err := s.propertyRepo.Create(property, newRevision(hostID, models.PropertyRevisionCreated))
+	if err != nil {
+		logger.Errorf("failed to create draft: %v", err)
+		return nil, err
+	}
+
+	return property.ToDraftResponse(), nil
+}
+
+// returns the property if the user hosts it or may edit it as a co-host
+func (s *PropertyService) getOwnProperty(propertyID, hostID uuid.UUID) (*models.Property, error) {
 	property, err := s.propertyRepo.GetPropertyByID(propertyID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -333,12 +784,249 @@ func (s *PropertyService) ApproveProperty(propertyID uuid.UUID) (*models.Propert
 		return nil, err
 	}
 
-	property.Status = models.PropertyStatusActive
-	err = s.propertyRepo.UpdateProperty(property)
+	if !s.cohostService.canManage(property, hostID, models.CoHostPermissionEditListing) {
+		return nil, errors.New("unauthorized: you can only update your own properties")
+	}
+
+	return property, nil
+}
+
+// GetDraft returns a listing of the host with what it still misses
+func (s *PropertyService) GetDraft(propertyID, hostID uuid.UUID) (*models.PropertyDraftResponse, error) {
+	property, err := s.getOwnProperty(propertyID, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	return property.ToDraftResponse(), nil
+}
+
+// applies one step to a draft or rejected listing of the host and saves it
+func (s *PropertyService) updateDraft(propertyID, hostID uuid.UUID, apply func(property *models.Property) error) (*models.PropertyDraftResponse, error) {
+	property, err := s.getOwnProperty(propertyID, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUnsubmitted(property.Status) {
+		return nil, errors.New("only drafts and rejected listings can be edited step by step")
+	}
+
+	if err := apply(property); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.propertyRepo.UpdateProperty(property, nil, newRevision(hostID, models.PropertyRevisionUpdated))
+	if err != nil {
+		logger.Errorf("failed to update draft: %v", err)
+		return nil, err
+	}
+	if !updated {
+		return nil, errors.New("the listing changed meanwhile, reload it and try again")
+	}
+	// drafts are never listed, only the cached copy can be stale
+	evictCached(s.caches.Properties, propertyID.String())
+
+	return property.ToDraftResponse(), nil
+}
+
+func (s *PropertyService) UpdateDraftBasics(propertyID, hostID uuid.UUID, req *models.DraftBasicsRequest) (*models.PropertyDraftResponse, error) {
+	return s.updateDraft(propertyID, hostID, func(property *models.Property) error {
+		return applyDraftBasics(property, req)
+	})
+}
+
+func (s *PropertyService) UpdateDraftLocation(propertyID, hostID uuid.UUID, req *models.DraftLocationRequest) (*models.PropertyDraftResponse, error) {
+	return s.updateDraft(propertyID, hostID, func(property *models.Property) error {
+		if req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90) {
+			return errors.New("invalid latitude")
+		}
+		if req.Longitude != nil && (*req.Longitude < -180 || *req.Longitude > 180) {
+			return errors.New("invalid longitude")
+		}
+
+		setTrimmed(&property.Address, req.Address)
+		setTrimmed(&property.City, req.City)
+		setTrimmed(&property.State, req.State)
+		setTrimmed(&property.Country, req.Country)
+		setTrimmed(&property.ZipCode, req.ZipCode)
+		if req.Latitude != nil {
+			property.Latitude = *req.Latitude
+		}
+		if req.Longitude != nil {
+			property.Longitude = *req.Longitude
+		}
+		return nil
+	})
+}
+
+func (s *PropertyService) UpdateDraftCapacity(propertyID, hostID uuid.UUID, req *models.DraftCapacityRequest) (*models.PropertyDraftResponse, error) {
+	return s.updateDraft(propertyID, hostID, func(property *models.Property) error {
+		if req.MaxGuests != nil && (*req.MaxGuests < 1 || *req.MaxGuests > 20) {
+			return errors.New("max guests must be between 1 and 20")
+		}
+		if req.Bedrooms != nil && (*req.Bedrooms < 0 || *req.Bedrooms > 20) {
+			return errors.New("bedrooms must be between 0 and 20")
+		}
+		if req.Bathrooms != nil && (*req.Bathrooms < 1 || *req.Bathrooms > 20) {
+			return errors.New("bathrooms must be between 1 and 20")
+		}
+		rooms, err := normalizeRooms(req.Rooms)
+		if err != nil {
+			return err
+		}
+
+		if req.MaxGuests != nil {
+			property.MaxGuests = *req.MaxGuests
+		}
+		if req.Bedrooms != nil {
+			property.Bedrooms = *req.Bedrooms
+		}
+		if req.Bathrooms != nil {
+			property.Bathrooms = *req.Bathrooms
+		}
+		if req.Rooms != nil {
+			property.Rooms = rooms
+			property.TotalBeds = rooms.TotalBeds()
+			if len(rooms) > 0 {
+				property.Bedrooms = rooms.Bedrooms()
+			}
+		}
+		return nil
+	})
+}
+
+func (s *PropertyService) UpdateDraftAmenities(propertyID, hostID uuid.UUID, req *models.DraftAmenitiesRequest) (*models.PropertyDraftResponse, error) {
+	amenities, err := s.amenityService.ResolveAmenities(req.Amenities)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateDraft(propertyID, hostID, func(property *models.Property) error {
+		property.Amenities = amenities
+		return nil
+	})
+}
+
+func (s *PropertyService) UpdateDraftPricing(propertyID, hostID uuid.UUID, req *models.DraftPricingRequest) (*models.PropertyDraftResponse, error) {
+	return s.updateDraft(propertyID, hostID, func(property *models.Property) error {
+		if req.PricePerNight != nil && *req.PricePerNight < 1 {
+			return errors.New("price per night must be at least 1")
+		}
+
+		if req.PricePerNight != nil {
+			property.PricePerNight = *req.PricePerNight
+		}
+		if req.Currency != nil {
+			currency := strings.ToUpper(strings.TrimSpace(*req.Currency))
+			if len(currency) != 3 {
+				return errors.New("invalid currency")
+			}
+			property.Currency = currency
+		}
+		return nil
+	})
+}
+
+func (s *PropertyService) UpdateDraftRules(propertyID, hostID uuid.UUID, req *models.DraftRulesRequest) (*models.PropertyDraftResponse, error) {
+	return s.updateDraft(propertyID, hostID, func(property *models.Property) error {
+		accessibility, err := normalizeAccessibilityFeatures(req.AccessibilityFeatures)
+		if err != nil {
+			return err
+		}
+
+		if req.Rules != nil {
+			property.Rules = req.Rules
+		}
+		if req.HouseRules != nil {
+			houseRules, err := normalizeHouseRules(*req.HouseRules)
+			if err != nil {
+				return err
+			}
+			property.HouseRules = houseRules
+		}
+		if req.AccessibilityFeatures != nil {
+			property.AccessibilityFeatures = accessibility
+		}
+		if req.AccessibilityNotes != nil {
+			property.AccessibilityNotes = strings.TrimSpace(*req.AccessibilityNotes)
+		}
+		if req.CheckInTime != nil {
+			property.CheckInTime = *req.CheckInTime
+		}
+		if req.CheckOutTime != nil {
+			property.CheckOutTime = *req.CheckOutTime
+		}
+		return nil
+	})
+}
+
+// SubmitDraft checks a draft like a listing created in one go and sends it
+// for approval. Rejected listings are submitted again the same way once the
+// host has fixed them.
+func (s *PropertyService) SubmitDraft(propertyID, hostID uuid.UUID) (*models.PropertyResponse, error) {
+	property, err := s.getOwnProperty(propertyID, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isUnsubmitted(property.Status) {
+		return nil, errors.New("only drafts and rejected listings can be submitted")
+	}
+
+	completeness := property.Completeness()
+	if len(completeness.MissingFields) > 0 {
+		return nil, fmt.Errorf("listing is incomplete: %s", strings.Join(completeness.MissingFields, ", "))
+	}
+
+	submittedAt := time.Now()
+	property.Status = models.PropertyStatusPending
+	property.SubmittedAt = &submittedAt
+	updated, err := s.propertyRepo.UpdateProperty(property, nil, newRevision(hostID, models.PropertyRevisionSubmitted))
 	if err != nil {
-		logger.Errorf("failed to approve property: %v", err)
+		logger.Errorf("failed to submit draft: %v", err)
 		return nil, err
 	}
+	if !updated {
+		return nil, errors.New("the listing changed meanwhile, reload it and try again")
+	}
+	s.evictProperty(propertyID)
 
 	return property.ToResponse(), nil
 }
+
+// IsIncompleteListingError reports whether err is the error SubmitDraft
+// returns for a draft that misses required fields
+func IsIncompleteListingError(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "listing is incomplete: ")
+}
+
+// reports whether a listing with the status still has to be submitted for
+// approval
+func isUnsubmitted(status models.PropertyStatus) bool {
+	return status == models.PropertyStatusDraft || status == models.PropertyStatusRejected
+}
+
+// applies the basics step, which is also accepted when creating a draft
+func applyDraftBasics(property *models.Property, req *models.DraftBasicsRequest) error {
+	if req.Title != nil && utf8.RuneCountInString(strings.TrimSpace(*req.Title)) > 100 {
+		return errors.New("title is too long")
+	}
+	if req.Type != nil && !models.IsValidPropertyType(*req.Type) {
+		return errors.New("invalid property type")
+	}
+
+	setTrimmed(&property.Title, req.Title)
+	setTrimmed(&property.Description, req.Description)
+	if req.Type != nil {
+		property.Type = *req.Type
+	}
+	return nil
+}
+
+// sets *field to the trimmed value when one is given
+func setTrimmed(field *string, value *string) {
+	if value != nil {
+		*field = strings.TrimSpace(*value)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+// rules a single translation can list
+const maxTranslatedRules = 50
+
+type PropertyTranslationService struct {
+	translationRepo repository.PropertyTranslationRepository
+	propertyRepo    repository.PropertyRepository
+	cohostService   *CoHostService
+	caches          *Caches
+}
+
+func NewPropertyTranslationService(translationRepo repository.PropertyTranslationRepository, propertyRepo repository.PropertyRepository, cohostService *CoHostService, caches *Caches) *PropertyTranslationService {
+	return &PropertyTranslationService{
+		translationRepo: translationRepo,
+		propertyRepo:    propertyRepo,
+		cohostService:   cohostService,
+		caches:          caches,
+	}
+}
+
+// returns the property if the user is its host or a co-host who may edit it,
+// or an admin when allowed
+func (s *PropertyTranslationService) getProperty(propertyID, userID uuid.UUID, isAdmin bool) (*models.Property, error) {
+	property, err := s.propertyRepo.GetPropertyByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		logger.Errorf("failed to get property: %v", err)
+		return nil, err
+	}
+
+	if !isAdmin && !s.cohostService.canManage(property, userID, models.CoHostPermissionEditListing) {
+		return nil, errors.New("unauthorized: you can only update your own properties")
+	}
+
+	return property, nil
+}
+
+// checks a locale translations can be added in and returns its canonical form
+func translationLocale(locale string) (string, error) {
+	locale, ok := models.NormalizeLocale(locale)
+	if !ok {
+		return "", errors.New("invalid locale")
+	}
+	if locale == models.DefaultLocale {
+		return "", errors.New("the original text is used for the default locale")
+	}
+	return locale, nil
+}
+
+// GetTranslations returns the translations of a listing to its host or an admin
+func (s *PropertyTranslationService) GetTranslations(propertyID, userID uuid.UUID, isAdmin bool) ([]*models.PropertyTranslationResponse, error) {
+	if _, err := s.getProperty(propertyID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	translations, err := s.translationRepo.GetTranslationsByPropertyID(propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get translations: %w", err)
+	}
+
+	responses := make([]*models.PropertyTranslationResponse, len(translations))
+	for i, translation := range translations {
+		responses[i] = translation.ToResponse()
+	}
+
+	return responses, nil
+}
+
+// SetTranslation adds or replaces the translation of a listing in a locale.
+// Fields left empty show the original text.
+func (s *PropertyTranslationService) SetTranslation(propertyID, hostID uuid.UUID, locale string, req *models.PropertyTranslationRequest) (*models.PropertyTranslationResponse, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &models.PropertyTranslation{
+		PropertyID:  propertyID,
+		Locale:      locale,
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		Rules:       []string{},
+	}
+	if utf8.RuneCountInString(translation.Title) > 100 {
+		return nil, errors.New("title is too long")
+	}
+	for _, rule := range req.Rules {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			translation.Rules = append(translation.Rules, rule)
+		}
+	}
+	if len(translation.Rules) > maxTranslatedRules {
+		return nil, errors.New("translation has too many rules")
+	}
+	if translation.Title == "" && translation.Description == "" && len(translation.Rules) == 0 {
+		return nil, errors.New("translation is empty")
+	}
+
+	if _, err := s.getProperty(propertyID, hostID, false); err != nil {
+		return nil, err
+	}
+
+	if err := s.translationRepo.UpsertTranslation(translation); err != nil {
+		return nil, fmt.Errorf("failed to save translation: %w", err)
+	}
+	// text searches match translations
+	invalidateCached(s.caches.Search)
+
+	return translation.ToResponse(), nil
+}
+
+func (s *PropertyTranslationService) DeleteTranslation(propertyID, hostID uuid.UUID, locale string) error {
+	locale, ok := models.NormalizeLocale(locale)
+	if !ok {
+		return errors.New("invalid locale")
+	}
+
+	if _, err := s.getProperty(propertyID, hostID, false); err != nil {
+		return err
+	}
+
+	if err := s.translationRepo.DeleteTranslation(propertyID, locale); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("translation not found")
+		}
+		return fmt.Errorf("failed to delete translation: %w", err)
+	}
+	invalidateCached(s.caches.Search)
+
+	return nil
+}
+
+// Localize returns copies of the properties with their text in the
+// translation that best suits the preferred languages, most wanted first.
+// Properties whose original text suits them better are returned as they are.
+func (s *PropertyTranslationService) Localize(properties []*models.PropertyResponse, preferred []language.Tag) ([]*models.PropertyResponse, error) {
+	bases := make([]string, 0, len(preferred))
+	for _, tag := range preferred {
+		if base, _ := tag.Base(); base.String() != models.DefaultLocale {
+			bases = append(bases, base.String())
+		}
+	}
+	if len(bases) == 0 || len(properties) == 0 {
+		return properties, nil
+	}
+
+	propertyIDs := make([]uuid.UUID, len(properties))
+	for i, property := range properties {
+		propertyIDs[i] = property.ID
+	}
+
+	translations, err := s.translationRepo.GetTranslations(propertyIDs, bases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get translations: %w", err)
+	}
+
+	byProperty := make(map[uuid.UUID]map[string]*models.PropertyTranslation, len(properties))
+	for _, translation := range translations {
+		if byProperty[translation.PropertyID] == nil {
+			byProperty[translation.PropertyID] = make(map[string]*models.PropertyTranslation)
+		}
+		byProperty[translation.PropertyID][translation.Locale] = translation
+	}
+
+	localized := make([]*models.PropertyResponse, len(properties))
+	for i, property := range properties {
+		localized[i] = property
+
+		byLocale := byProperty[property.ID]
+		if len(byLocale) == 0 {
+			continue
+		}
+		available := make([]string, 0, len(byLocale))
+		for locale := range byLocale {
+			available = append(available, locale)
+		}
+		// map order is random, the matcher prefers earlier locales on ties
+		sort.Strings(available)
+
+		if translation, ok := byLocale[models.MatchLocale(preferred, available)]; ok {
+			localized[i] = property.Translate(translation)
+		}
+	}
+
+	return localized, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"airbnb-clone/internal/cache"
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// properties kept in each user's recently viewed list
+	maxRecentlyViewed = 50
+	// the list is dropped after this long without a new view
+	recentlyViewedTTL = 90 * 24 * time.Hour
+)
+
+// RecentlyViewedService keeps a short list of the properties each user looked
+// at, most recent first, in Redis
+type RecentlyViewedService struct {
+	redisClient  *cache.RedisClient
+	propertyRepo repository.PropertyRepository
+	userService  *UserService
+}
+
+func NewRecentlyViewedService(redisClient *cache.RedisClient, propertyRepo repository.PropertyRepository, userService *UserService) *RecentlyViewedService {
+	return &RecentlyViewedService{
+		redisClient:  redisClient,
+		propertyRepo: propertyRepo,
+		userService:  userService,
+	}
+}
+
+func recentlyViewedKey(userID uuid.UUID) string {
+	return fmt.Sprintf("recently_viewed:%s", userID)
+}
+
+// RecordView moves the property to the front of the user's list, unless the
+// user opted out
+func (s *RecentlyViewedService) RecordView(userID, propertyID uuid.UUID) error {
+	user, err := s.userService.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+	if user.RecentlyViewedOptOut {
+		return nil
+	}
+
+	err = s.redisClient.PushUnique(recentlyViewedKey(userID), propertyID.String(), maxRecentlyViewed, recentlyViewedTTL)
+	if err != nil {
+		return fmt.Errorf("failed to record property view: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecentlyViewed returns up to limit properties the user viewed, most
+// recent first. Properties that were deleted or are no longer active are left
+// out and dropped from the list.
+func (s *RecentlyViewedService) GetRecentlyViewed(userID uuid.UUID, limit int) ([]*models.PropertyResponse, error) {
+	if limit <= 0 || limit > maxRecentlyViewed {
+		limit = 20
+	}
+
+	user, err := s.userService.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.RecentlyViewedOptOut {
+		return []*models.PropertyResponse{}, nil
+	}
+
+	key := recentlyViewedKey(userID)
+	values, err := s.redisClient.LRange(key, 0, maxRecentlyViewed-1)
+	if err != nil {
+		logger.Errorf("failed to get recently viewed properties: %v", err)
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	properties, err := s.propertyRepo.GetActivePropertiesByIDs(ids)
+	if err != nil {
+		logger.Errorf("failed to get recently viewed properties: %v", err)
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*models.Property, len(properties))
+	for _, property := range properties {
+		byID[property.ID] = property
+	}
+
+	responses := make([]*models.PropertyResponse, 0, limit)
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		property, ok := byID[id]
+		if err != nil || !ok {
+			// the property is gone or was taken off the market
+			if err := s.redisClient.LRem(key, 0, value); err != nil {
+				logger.Errorf("failed to drop stale recently viewed property: %v", err)
+			}
+			continue
+		}
+		if len(responses) < limit {
+			responses = append(responses, property.ToResponse())
+		}
+	}
+
+	return responses, nil
+}
+
+// ClearRecentlyViewed forgets every property the user viewed
+func (s *RecentlyViewedService) ClearRecentlyViewed(userID uuid.UUID) error {
+	if err := clearRecentlyViewed(s.redisClient, userID); err != nil {
+		return fmt.Errorf("failed to clear recently viewed properties: %w", err)
+	}
+	return nil
+}
+
+// also used by UserService, so opting out forgets what was recorded so far
+// whichever way the setting is changed
+func clearRecentlyViewed(redisClient *cache.RedisClient, userID uuid.UUID) error {
+	return redisClient.Del(recentlyViewedKey(userID))
+}
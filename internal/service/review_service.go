@@ -1,6 +1,8 @@
 package service
 
 import (
+	"airbnb-clone/internal/cache"
+	"airbnb-clone/internal/logger"
 	"airbnb-clone/internal/models"
 	"airbnb-clone/internal/repository"
 	"errors"
@@ -11,17 +13,34 @@ import (
 )
 
 type ReviewService struct {
-	reviewRepo  repository.ReviewRepository
-	bookingRepo repository.BookingRepository
+	reviewRepo   repository.ReviewRepository
+	bookingRepo  repository.BookingRepository
+	propertyRepo repository.PropertyRepository
+	caches       *Caches
 }
 
-func NewReviewService(reviewRepo repository.ReviewRepository, bookingRepo repository.BookingRepository) *ReviewService {
+func NewReviewService(reviewRepo repository.ReviewRepository, bookingRepo repository.BookingRepository, propertyRepo repository.PropertyRepository, caches *Caches) *ReviewService {
 	return &ReviewService{
-		reviewRepo:  reviewRepo,
-		bookingRepo: bookingRepo,
+		reviewRepo:   reviewRepo,
+		bookingRepo:  bookingRepo,
+		propertyRepo: propertyRepo,
+		caches:       caches,
 	}
 }
 
+// keeps the denormalized rating of a property in line with its reviews
+func (s *ReviewService) refreshPropertyRating(propertyID uuid.UUID) {
+	if err := s.propertyRepo.UpdateRatingSummary(propertyID); err != nil {
+		logger.Errorf("failed to update rating summary for property %s: %v", propertyID, err)
+	}
+
+	// the rating is part of the property, its rating summary and the
+	// search results that sort by it
+	evictCached(s.caches.Ratings, propertyID.String())
+	evictCached(s.caches.Properties, propertyID.String())
+	invalidateCached(s.caches.Search)
+}
+
 func (s *ReviewService) CreateReview(reviewerID uuid.UUID, req *models.ReviewCreateRequest) (*models.ReviewResponse, error) {
 	// Get booking details to validate the review
 	booking, err := s.bookingRepo.GetBookingByID(req.BookingID)
@@ -61,6 +80,8 @@ func (s *ReviewService) CreateReview(reviewerID uuid.UUID, req *models.ReviewCre
 		return nil, fmt.Errorf("failed to create review: %w", err)
 	}
 
+	s.refreshPropertyRating(review.PropertyID)
+
 	createdReview, err := s.reviewRepo.GetReviewByID(review.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch created review: %w", err)
@@ -106,6 +127,10 @@ func (s *ReviewService) UpdateReview(reviewID, reviewerID uuid.UUID, req *models
 		return nil, fmt.Errorf("failed to update review: %w", err)
 	}
 
+	if req.Rating > 0 {
+		s.refreshPropertyRating(review.PropertyID)
+	}
+
 	return review.ToResponse(), nil
 }
 
@@ -128,82 +153,88 @@ func (s *ReviewService) DeleteReview(reviewID, reviewerID uuid.UUID, userRole st
 		return fmt.Errorf("failed to delete review: %w", err)
 	}
 
+	s.refreshPropertyRating(review.PropertyID)
+
 	return nil
 }
 
-func (s *ReviewService) GetPropertyReviews(propertyID uuid.UUID, page, limit int) ([]*models.ReviewResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
+func (s *ReviewService) GetPropertyReviews(propertyID uuid.UUID, page, limit int, cursor string) ([]*models.ReviewResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
 	}
-	offset := (page - 1) * limit
 
-	reviews, err := s.reviewRepo.GetReviewsByPropertyID(propertyID, offset, limit)
+	reviews, err := s.reviewRepo.GetReviewsByPropertyID(propertyID, pageQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get property reviews: %w", err)
+		return nil, "", fmt.Errorf("failed to get property reviews: %w", err)
 	}
+	reviews, nextCursor := models.TrimPage(reviews, pageQuery, (*models.Review).PageCursor)
 
 	responses := make([]*models.ReviewResponse, len(reviews))
 	for i, review := range reviews {
 		responses[i] = review.ToResponse()
 	}
 
-	return responses, nil
+	return responses, nextCursor, nil
 }
 
-func (s *ReviewService) GetUserReviews(userID uuid.UUID, page, limit int) ([]*models.ReviewResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
+func (s *ReviewService) GetUserReviews(userID uuid.UUID, page, limit int, cursor string) ([]*models.ReviewResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
 	}
-	offset := (page - 1) * limit
 
-	reviews, err := s.reviewRepo.GetReviewsByUserID(userID, offset, limit)
+	reviews, err := s.reviewRepo.GetReviewsByUserID(userID, pageQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user reviews: %w", err)
+		return nil, "", fmt.Errorf("failed to get user reviews: %w", err)
 	}
+	reviews, nextCursor := models.TrimPage(reviews, pageQuery, (*models.Review).PageCursor)
 
 	responses := make([]*models.ReviewResponse, len(reviews))
 	for i, review := range reviews {
 		responses[i] = review.ToResponse()
 	}
 
-	return responses, nil
+	return responses, nextCursor, nil
 }
 
 // GetAllReviews gets all reviews (admin only)
-func (s *ReviewService) GetAllReviews(page, limit int) ([]*models.ReviewResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 {
-		limit = 20
+func (s *ReviewService) GetAllReviews(page, limit int, cursor string) ([]*models.ReviewResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
 	}
-	offset := (page - 1) * limit
 
-	reviews, err := s.reviewRepo.ListReviews(offset, limit)
+	reviews, err := s.reviewRepo.ListReviews(pageQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all reviews: %w", err)
+		return nil, "", fmt.Errorf("failed to get all reviews: %w", err)
 	}
+	reviews, nextCursor := models.TrimPage(reviews, pageQuery, (*models.Review).PageCursor)
 
 	responses := make([]*models.ReviewResponse, len(reviews))
 	for i, review := range reviews {
 		responses[i] = review.ToResponse()
 	}
 
-	return responses, nil
+	return responses, nextCursor, nil
+}
+
+func (s *ReviewService) GetPropertyRating(propertyID uuid.UUID) (*models.PropertyRatingResponse, error) {
+	return cache.GetOrLoad(s.caches.Ratings, propertyID.String(), func() (*models.PropertyRatingResponse, error) {
+		summary, err := s.reviewRepo.GetRatingSummary(propertyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rating summary: %w", err)
+		}
+
+		return summary, nil
+	})
 }
 
 func (s *ReviewService) GetPropertyAverageRating(propertyID uuid.UUID) (float64, error) {
-	avgRating, err := s.reviewRepo.GetAverageRating(propertyID)
+	summary, err := s.GetPropertyRating(propertyID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get average rating: %w", err)
+		return 0, err
 	}
 
-	return avgRating, nil
+	return summary.AverageRating, nil
 }
-
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"airbnb-clone/internal/logger"
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/notification"
+	"airbnb-clone/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// saved searches loaded per batch by the matcher
+	savedSearchBatchSize = 100
+	// listings named in a single alert
+	maxAlertListings = 10
+)
+
+type SavedSearchService struct {
+	savedSearchRepo repository.SavedSearchRepository
+	propertyRepo    repository.PropertyRepository
+	amenityService  *AmenityService
+	notifier        notification.Notifier
+}
+
+func NewSavedSearchService(savedSearchRepo repository.SavedSearchRepository, propertyRepo repository.PropertyRepository, amenityService *AmenityService, notifier notification.Notifier) *SavedSearchService {
+	return &SavedSearchService{
+		savedSearchRepo: savedSearchRepo,
+		propertyRepo:    propertyRepo,
+		amenityService:  amenityService,
+		notifier:        notifier,
+	}
+}
+
+func (s *SavedSearchService) CreateSavedSearch(userID uuid.UUID, req *models.SavedSearchCreateRequest) (*models.SavedSearchResponse, error) {
+	criteria, err := normalizeSearchCriteria(req.Criteria)
+	if err != nil {
+		return nil, err
+	}
+	criteria.Amenities, err = s.amenityService.ResolveAmenities(criteria.Amenities)
+	if err != nil {
+		return nil, err
+	}
+
+	search := &models.SavedSearch{
+		UserID:        userID,
+		Name:          strings.TrimSpace(req.Name),
+		Criteria:      criteria,
+		AlertsEnabled: true,
+	}
+	if req.AlertsEnabled != nil {
+		search.AlertsEnabled = *req.AlertsEnabled
+	}
+	if search.Name == "" {
+		return nil, errors.New("saved search name is required")
+	}
+
+	err = s.savedSearchRepo.CreateSavedSearch(search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	return search.ToResponse(), nil
+}
+
+// returns the saved search if it belongs to the user. Searches of other users
+// are reported as not found so their ids are not disclosed.
+func (s *SavedSearchService) getOwnSavedSearch(searchID, userID uuid.UUID) (*models.SavedSearch, error) {
+	search, err := s.savedSearchRepo.GetSavedSearchByID(searchID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("saved search not found")
+		}
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+
+	if search.UserID != userID {
+		return nil, errors.New("saved search not found")
+	}
+
+	return search, nil
+}
+
+func (s *SavedSearchService) GetSavedSearch(searchID, userID uuid.UUID) (*models.SavedSearchResponse, error) {
+	search, err := s.getOwnSavedSearch(searchID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return search.ToResponse(), nil
+}
+
+func (s *SavedSearchService) GetUserSavedSearches(userID uuid.UUID, page, limit int, cursor string) ([]*models.SavedSearchResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	searches, err := s.savedSearchRepo.GetSavedSearchesByUserID(userID, pageQuery)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	searches, nextCursor := models.TrimPage(searches, pageQuery, (*models.SavedSearch).PageCursor)
+
+	responses := make([]*models.SavedSearchResponse, len(searches))
+	for i, search := range searches {
+		responses[i] = search.ToResponse()
+	}
+
+	return responses, nextCursor, nil
+}
+
+func (s *SavedSearchService) UpdateSavedSearch(searchID, userID uuid.UUID, req *models.SavedSearchUpdateRequest) (*models.SavedSearchResponse, error) {
+	search, err := s.getOwnSavedSearch(searchID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		search.Name = strings.TrimSpace(req.Name)
+		if search.Name == "" {
+			return nil, errors.New("saved search name is required")
+		}
+	}
+	if req.Criteria != nil {
+		criteria, err := normalizeSearchCriteria(*req.Criteria)
+		if err != nil {
+			return nil, err
+		}
+		criteria.Amenities, err = s.amenityService.ResolveAmenities(criteria.Amenities)
+		if err != nil {
+			return nil, err
+		}
+		search.Criteria = criteria
+	}
+	if req.AlertsEnabled != nil {
+		search.AlertsEnabled = *req.AlertsEnabled
+	}
+
+	err = s.savedSearchRepo.UpdateSavedSearch(search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update saved search: %w", err)
+	}
+
+	return search.ToResponse(), nil
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(searchID, userID uuid.UUID) error {
+	if _, err := s.getOwnSavedSearch(searchID, userID); err != nil {
+		return err
+	}
+
+	err := s.savedSearchRepo.DeleteSavedSearch(searchID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	return nil
+}
+
+// StartMatcher runs the saved search alerts every interval until ctx is done.
+// A non-positive interval disables the alerts.
+func (s *SavedSearchService) StartMatcher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		logger.Warn("saved search alerts are disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RunAlerts(); err != nil {
+				logger.Errorf("saved search matcher failed: %v", err)
+			}
+		}
+	}
+}
+
+// RunAlerts checks every saved search with alerts on against the listings
+// that were added, updated or had dates freed since it last ran, and notifies
+// its owner of any matches. Only one instance of the server runs them at a
+// time, the others skip the run.
+func (s *SavedSearchService) RunAlerts() error {
+	ran, err := s.savedSearchRepo.WithMatcherLock(s.runAlerts)
+	if err == nil && !ran {
+		logger.Debug("saved search alerts are already running on another instance")
+	}
+	return err
+}
+
+func (s *SavedSearchService) runAlerts() error {
+	afterID := uuid.Nil
+	for {
+		searches, err := s.savedSearchRepo.GetSavedSearchesWithAlerts(afterID, savedSearchBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to load saved searches: %w", err)
+		}
+
+		for _, search := range searches {
+			if err := s.runAlert(search); err != nil {
+				// one failing search must not hold back the others
+				logger.Errorf("failed to run saved search %s: %v", search.ID, err)
+			}
+		}
+
+		if len(searches) < savedSearchBatchSize {
+			return nil
+		}
+		afterID = searches[len(searches)-1].ID
+	}
+}
+
+func (s *SavedSearchService) runAlert(search *models.SavedSearch) error {
+	// changes made while this run is in progress are picked up by the next one
+	runAt := time.Now()
+
+	req := search.SearchRequest()
+	if !req.CheckOut.IsZero() && req.CheckOut.Before(runAt) {
+		// the requested stay is over, nothing can match any more
+		return s.savedSearchRepo.MarkSavedSearchRun(search.ID, runAt)
+	}
+
+	since := search.CreatedAt
+	if search.LastRunAt != nil {
+		since = *search.LastRunAt
+	}
+	req.ChangedSince = &since
+	// searches saved before the amenity catalog may use other spellings, and
+	// amenities removed from the catalog since no longer filter anything
+	amenities, unknown, err := s.amenityService.ResolveKnownAmenities(req.Amenities)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		logger.Warnf("saved search %s ignores amenities that left the catalog: %s", search.ID, strings.Join(unknown, ", "))
+	}
+	req.Amenities = amenities
+	req.Page = 1
+	req.Limit = maxAlertListings
+	if req.Sort == "" {
+		req.Sort = models.SearchSortNewest
+	}
+
+	properties, total, err := s.propertyRepo.SearchProperties(req)
+	if err != nil {
+		return err
+	}
+
+	if total > 0 {
+		propertyIDs := make([]uuid.UUID, len(properties))
+		titles := make([]string, len(properties))
+		for i, property := range properties {
+			propertyIDs[i] = property.ID
+			titles[i] = property.Title
+		}
+
+		err = s.notifier.Notify(&notification.Notification{
+			Type:    notification.TypeSavedSearchMatch,
+			UserID:  search.UserID,
+			Email:   search.User.Email,
+			Subject: fmt.Sprintf("%d new or updated listings match \"%s\"", total, search.Name),
+			Body:    strings.Join(titles, "\n"),
+			Data: map[string]interface{}{
+				"saved_search_id": search.ID,
+				"property_ids":    propertyIDs,
+				"total":           total,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send notification: %w", err)
+		}
+	}
+
+	return s.savedSearchRepo.MarkSavedSearchRun(search.ID, runAt)
+}
+
+// strips the paging of a search and checks that it can be run by the matcher
+func normalizeSearchCriteria(req models.PropertySearchRequest) (models.SearchCriteria, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	req.City = strings.TrimSpace(req.City)
+	req.State = strings.TrimSpace(req.State)
+	req.Country = strings.TrimSpace(req.Country)
+	req.Type = strings.TrimSpace(req.Type)
+	req.Page = 0
+	req.Limit = 0
+	req.ChangedSince = nil
+
+	// the same limits as a live search, which the matcher runs it as
+	if req.Lat != nil && (*req.Lat < -90 || *req.Lat > 90) {
+		return models.SearchCriteria{}, errors.New("invalid lat")
+	}
+	if req.Lng != nil && (*req.Lng < -180 || *req.Lng > 180) {
+		return models.SearchCriteria{}, errors.New("invalid lng")
+	}
+	if (req.Lat == nil) != (req.Lng == nil) {
+		return models.SearchCriteria{}, errors.New("lat and lng must be provided together")
+	}
+	if req.RadiusKm < 0 || req.RadiusKm > models.MaxSearchRadiusKm {
+		return models.SearchCriteria{}, fmt.Errorf("invalid radius_km. Must be between 0 and %d", models.MaxSearchRadiusKm)
+	}
+	if req.RadiusKm > 0 && req.Lat == nil {
+		return models.SearchCriteria{}, errors.New("radius_km requires lat and lng")
+	}
+	if req.BBox != nil {
+		if err := req.BBox.Validate(); err != nil {
+			return models.SearchCriteria{}, err
+		}
+	}
+	if req.Sort != "" && !models.IsValidSearchSort(req.Sort) {
+		return models.SearchCriteria{}, errors.New("invalid sort")
+	}
+	if req.Sort == models.SearchSortDistance && (req.Lat == nil || req.Lng == nil) {
+		return models.SearchCriteria{}, errors.New("sort by distance requires lat and lng")
+	}
+	if req.Sort == models.SearchSortRelevance && req.Query == "" {
+		return models.SearchCriteria{}, errors.New("sort by relevance requires q")
+	}
+	if req.CheckIn.IsZero() != req.CheckOut.IsZero() {
+		return models.SearchCriteria{}, errors.New("check-in and check-out dates must be given together")
+	}
+	if !req.CheckIn.IsZero() && !req.CheckOut.After(req.CheckIn) {
+		return models.SearchCriteria{}, errors.New("check-out date must be after check-in date")
+	}
+
+	accessibility, err := normalizeAccessibilityFeatures(req.Accessibility)
+	if err != nil {
+		return models.SearchCriteria{}, err
+	}
+	req.Accessibility = accessibility
+
+	return models.SearchCriteria(req), nil
+}
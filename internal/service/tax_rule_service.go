@@ -0,0 +1,163 @@
+package service
+
+import (
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaxRuleService struct {
+	taxRuleRepo repository.TaxRuleRepository
+}
+
+func NewTaxRuleService(taxRuleRepo repository.TaxRuleRepository) *TaxRuleService {
+	return &TaxRuleService{
+		taxRuleRepo: taxRuleRepo,
+	}
+}
+
+func (s *TaxRuleService) CreateTaxRule(req *models.TaxRuleCreateRequest) (*models.TaxRule, error) {
+	rule := &models.TaxRule{
+		Name:      strings.TrimSpace(req.Name),
+		Country:   strings.TrimSpace(req.Country),
+		State:     strings.TrimSpace(req.State),
+		City:      strings.TrimSpace(req.City),
+		Type:      req.Type,
+		Rate:      req.Rate,
+		MaxNights: req.MaxNights,
+		IsActive:  true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := validateTaxRule(rule); err != nil {
+		return nil, err
+	}
+
+	err := s.taxRuleRepo.CreateTaxRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tax rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *TaxRuleService) GetTaxRule(ruleID uuid.UUID) (*models.TaxRule, error) {
+	rule, err := s.taxRuleRepo.GetTaxRuleByID(ruleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tax rule not found")
+		}
+		return nil, fmt.Errorf("failed to get tax rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *TaxRuleService) UpdateTaxRule(ruleID uuid.UUID, req *models.TaxRuleUpdateRequest) (*models.TaxRule, error) {
+	rule, err := s.GetTaxRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		rule.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Country != "" {
+		rule.Country = strings.TrimSpace(req.Country)
+	}
+	if req.State != nil {
+		rule.State = strings.TrimSpace(*req.State)
+	}
+	if req.City != nil {
+		rule.City = strings.TrimSpace(*req.City)
+	}
+	if req.Type != "" {
+		rule.Type = req.Type
+	}
+	if req.Rate != nil {
+		rule.Rate = *req.Rate
+	}
+	if req.MaxNights != nil {
+		rule.MaxNights = *req.MaxNights
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := validateTaxRule(rule); err != nil {
+		return nil, err
+	}
+
+	err = s.taxRuleRepo.UpdateTaxRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tax rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *TaxRuleService) DeleteTaxRule(ruleID uuid.UUID) error {
+	if _, err := s.GetTaxRule(ruleID); err != nil {
+		return err
+	}
+
+	err := s.taxRuleRepo.DeleteTaxRule(ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tax rule: %w", err)
+	}
+
+	return nil
+}
+
+func (s *TaxRuleService) ListTaxRules(page, limit int, cursor string) ([]*models.TaxRule, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rules, err := s.taxRuleRepo.ListTaxRules(pageQuery)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list tax rules: %w", err)
+	}
+	rules, nextCursor := models.TrimPage(rules, pageQuery, (*models.TaxRule).PageCursor)
+
+	return rules, nextCursor, nil
+}
+
+func validateTaxRule(rule *models.TaxRule) error {
+	if rule.Name == "" {
+		return errors.New("tax rule name is required")
+	}
+	if rule.Country == "" {
+		return errors.New("tax rule country is required")
+	}
+	if rule.City != "" && rule.State == "" {
+		return errors.New("tax rule state is required when city is set")
+	}
+
+	switch rule.Type {
+	case models.TaxRuleTypePercentage:
+		if rule.Rate > 100 {
+			return errors.New("percentage tax rate cannot exceed 100")
+		}
+	case models.TaxRuleTypePerNight, models.TaxRuleTypePerGuest:
+	default:
+		return errors.New("invalid tax rule type")
+	}
+
+	if rule.Rate < 0 {
+		return errors.New("tax rate cannot be negative")
+	}
+	if rule.MaxNights < 0 {
+		return errors.New("max nights cannot be negative")
+	}
+
+	return nil
+}
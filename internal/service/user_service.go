@@ -3,24 +3,31 @@ package service
 import (
 	"airbnb-clone/internal/logger"
 	"errors"
+	"strings"
 
+	"airbnb-clone/internal/cache"
 	"airbnb-clone/internal/config"
 	"airbnb-clone/internal/models"
 	"airbnb-clone/internal/repository"
 	"airbnb-clone/internal/utils"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserService struct {
-	userRepo   repository.UserRepository
-	jwtManager *utils.JWTManager
+	userRepo    repository.UserRepository
+	jwtManager  *utils.JWTManager
+	redisClient *cache.RedisClient
+	caches      *Caches
 }
 
-func NewUserService(userRepo repository.UserRepository, jwtConfig config.JWTConfig) *UserService {
+func NewUserService(userRepo repository.UserRepository, jwtConfig config.JWTConfig, redisClient *cache.RedisClient, caches *Caches) *UserService {
 	return &UserService{
-		userRepo:   userRepo,
-		jwtManager: utils.NewJWTManager(jwtConfig),
+		userRepo:    userRepo,
+		jwtManager:  utils.NewJWTManager(jwtConfig),
+		redisClient: redisClient,
+		caches:      caches,
 	}
 }
 
@@ -144,3 +151,69 @@ func (s *UserService) RefreshToken(refreshToken string) (*LoginResponse, error)
 		RefreshToken: newRefreshToken,
 	}, nil
 }
+
+func (s *UserService) GetProfile(userID uuid.UUID) (*models.UserResponse, error) {
+	return cache.GetOrLoad(s.caches.Users, userID.String(), func() (*models.UserResponse, error) {
+		user, err := s.userRepo.GetUserByID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("user not found")
+			}
+			logger.Errorf("failed to get user: %v", err)
+			return nil, err
+		}
+
+		return user.ToResponse(), nil
+	})
+}
+
+func (s *UserService) UpdateProfile(userID uuid.UUID, req *models.UserUpdateRequest) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		logger.Errorf("failed to get user: %v", err)
+		return nil, err
+	}
+
+	if req.FirstName != "" {
+		user.FirstName = strings.TrimSpace(req.FirstName)
+	}
+	if req.LastName != "" {
+		user.LastName = strings.TrimSpace(req.LastName)
+	}
+	if req.Phone != "" {
+		user.Phone = strings.TrimSpace(req.Phone)
+	}
+	if req.Avatar != "" {
+		user.Avatar = req.Avatar
+	}
+	if req.Bio != "" {
+		user.Bio = req.Bio
+	}
+	if req.RecentlyViewedOptOut != nil {
+		user.RecentlyViewedOptOut = *req.RecentlyViewedOptOut
+	}
+
+	err = s.userRepo.UpdateUser(user)
+	if err != nil {
+		logger.Errorf("failed to update user: %v", err)
+		return nil, err
+	}
+
+	// write the fresh profile through so readers never see the old one
+	response := user.ToResponse()
+	if err := s.caches.Users.Set(userID.String(), response); err != nil {
+		logger.Errorf("failed to cache user profile: %v", err)
+		evictCached(s.caches.Users, userID.String())
+	}
+
+	if user.RecentlyViewedOptOut {
+		if err := clearRecentlyViewed(s.redisClient, userID); err != nil {
+			logger.Errorf("failed to clear recently viewed properties: %v", err)
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"airbnb-clone/internal/models"
+	"airbnb-clone/internal/repository"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WishlistService struct {
+	wishlistRepo repository.WishlistRepository
+	propertyRepo repository.PropertyRepository
+	userRepo     repository.UserRepository
+}
+
+func NewWishlistService(wishlistRepo repository.WishlistRepository, propertyRepo repository.PropertyRepository, userRepo repository.UserRepository) *WishlistService {
+	return &WishlistService{
+		wishlistRepo: wishlistRepo,
+		propertyRepo: propertyRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func (s *WishlistService) CreateWishlist(ownerID uuid.UUID, req *models.WishlistCreateRequest) (*models.WishlistResponse, error) {
+	wishlist := &models.Wishlist{
+		OwnerID:    ownerID,
+		Name:       strings.TrimSpace(req.Name),
+		Visibility: models.WishlistVisibilityPrivate,
+	}
+	if wishlist.Name == "" {
+		return nil, errors.New("wishlist name is required")
+	}
+	if req.Visibility != "" {
+		if err := setWishlistVisibility(wishlist, req.Visibility); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.wishlistRepo.CreateWishlist(wishlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wishlist: %w", err)
+	}
+
+	return wishlist.ToResponse(true), nil
+}
+
+// returns the wishlist if the user is its owner or a collaborator. Other
+// users get "wishlist not found" so private wishlists are not disclosed.
+func (s *WishlistService) getMemberWishlist(wishlistID, userID uuid.UUID) (*models.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.GetWishlistByID(wishlistID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("wishlist not found")
+		}
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+
+	if !isWishlistMember(wishlist, userID) {
+		return nil, errors.New("wishlist not found")
+	}
+
+	return wishlist, nil
+}
+
+// returns the wishlist if the user is its owner
+func (s *WishlistService) getOwnWishlist(wishlistID, userID uuid.UUID) (*models.Wishlist, error) {
+	wishlist, err := s.getMemberWishlist(wishlistID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlist.OwnerID != userID {
+		return nil, errors.New("unauthorized: only the wishlist owner can do this")
+	}
+
+	return wishlist, nil
+}
+
+func isWishlistMember(wishlist *models.Wishlist, userID uuid.UUID) bool {
+	if wishlist.OwnerID == userID {
+		return true
+	}
+	for _, collaborator := range wishlist.Collaborators {
+		if collaborator.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WishlistService) GetWishlist(wishlistID, userID uuid.UUID) (*models.WishlistResponse, error) {
+	wishlist, err := s.getMemberWishlist(wishlistID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return wishlist.ToResponse(true), nil
+}
+
+// returns a wishlist through its share link
+func (s *WishlistService) GetSharedWishlist(token string) (*models.WishlistResponse, error) {
+	wishlist, err := s.wishlistRepo.GetWishlistByShareToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("wishlist not found")
+		}
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+
+	return wishlist.ToResponse(false), nil
+}
+
+func (s *WishlistService) GetUserWishlists(userID uuid.UUID, page, limit int, cursor string) ([]*models.WishlistResponse, string, error) {
+	pageQuery, err := models.NewPageQuery(page, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wishlists, err := s.wishlistRepo.GetWishlistsForUser(userID, pageQuery)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get wishlists: %w", err)
+	}
+	wishlists, nextCursor := models.TrimPage(wishlists, pageQuery, (*models.Wishlist).PageCursor)
+
+	responses := make([]*models.WishlistResponse, len(wishlists))
+	for i, wishlist := range wishlists {
+		responses[i] = wishlist.ToResponse(true)
+	}
+
+	return responses, nextCursor, nil
+}
+
+func (s *WishlistService) UpdateWishlist(wishlistID, userID uuid.UUID, req *models.WishlistUpdateRequest) (*models.WishlistResponse, error) {
+	wishlist, err := s.getOwnWishlist(wishlistID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		wishlist.Name = strings.TrimSpace(req.Name)
+		if wishlist.Name == "" {
+			return nil, errors.New("wishlist name is required")
+		}
+	}
+	if req.Visibility != "" {
+		if err := setWishlistVisibility(wishlist, req.Visibility); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.wishlistRepo.UpdateWishlist(wishlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update wishlist: %w", err)
+	}
+
+	return wishlist.ToResponse(true), nil
+}
+
+// switches the visibility of a wishlist. Sharing issues a new share token and
+// making the wishlist private again revokes it.
+func setWishlistVisibility(wishlist *models.Wishlist, visibility models.WishlistVisibility) error {
+	switch visibility {
+	case models.WishlistVisibilityPrivate:
+		wishlist.ShareToken = nil
+	case models.WishlistVisibilityShared:
+		if wishlist.ShareToken == nil {
+			token, err := newShareToken()
+			if err != nil {
+				return err
+			}
+			wishlist.ShareToken = &token
+		}
+	default:
+		return errors.New("invalid wishlist visibility")
+	}
+
+	wishlist.Visibility = visibility
+	return nil
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *WishlistService) DeleteWishlist(wishlistID, userID uuid.UUID) error {
+	if _, err := s.getOwnWishlist(wishlistID, userID); err != nil {
+		return err
+	}
+
+	err := s.wishlistRepo.DeleteWishlist(wishlistID)
+	if err != nil {
+		return fmt.Errorf("failed to delete wishlist: %w", err)
+	}
+
+	return nil
+}
+
+func (s *WishlistService) AddItem(wishlistID, userID uuid.UUID, req *models.WishlistItemCreateRequest) (*models.WishlistItemResponse, error) {
+	wishlist, err := s.getMemberWishlist(wishlistID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWishlistDates(req.CheckIn, req.CheckOut); err != nil {
+		return nil, err
+	}
+
+	for _, item := range wishlist.Items {
+		if item.PropertyID == req.PropertyID {
+			return nil, errors.New("property is already in this wishlist")
+		}
+	}
+
+	property, err := s.propertyRepo.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("property not found")
+		}
+		return nil, fmt.Errorf("failed to get property: %w", err)
+	}
+	// drafts and listings under review are not public yet
+	if property.Status != models.PropertyStatusActive {
+		return nil, errors.New("property not found")
+	}
+
+	item := &models.WishlistItem{
+		WishlistID: wishlistID,
+		PropertyID: property.ID,
+		AddedByID:  userID,
+		Notes:      strings.TrimSpace(req.Notes),
+		CheckIn:    req.CheckIn,
+		CheckOut:   req.CheckOut,
+	}
+
+	err = s.wishlistRepo.AddItem(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+
+	item.Property = *property
+	return item.ToResponse(true), nil
+}
+
+// returns an item of a wishlist the user is a member of
+func (s *WishlistService) getMemberItem(wishlistID, itemID, userID uuid.UUID) (*models.WishlistItem, error) {
+	if _, err := s.getMemberWishlist(wishlistID, userID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.wishlistRepo.GetItemByID(itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("wishlist item not found")
+		}
+		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
+	}
+
+	if item.WishlistID != wishlistID {
+		return nil, errors.New("wishlist item not found")
+	}
+
+	return item, nil
+}
+
+func (s *WishlistService) UpdateItem(wishlistID, itemID, userID uuid.UUID, req *models.WishlistItemUpdateRequest) (*models.WishlistItemResponse, error) {
+	item, err := s.getMemberItem(wishlistID, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Notes != nil {
+		item.Notes = strings.TrimSpace(*req.Notes)
+	}
+	if req.CheckIn != nil {
+		item.CheckIn = req.CheckIn
+	}
+	if req.CheckOut != nil {
+		item.CheckOut = req.CheckOut
+	}
+
+	if err := validateWishlistDates(item.CheckIn, item.CheckOut); err != nil {
+		return nil, err
+	}
+
+	err = s.wishlistRepo.UpdateItem(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update wishlist item: %w", err)
+	}
+
+	return item.ToResponse(true), nil
+}
+
+func (s *WishlistService) RemoveItem(wishlistID, itemID, userID uuid.UUID) error {
+	if _, err := s.getMemberItem(wishlistID, itemID, userID); err != nil {
+		return err
+	}
+
+	err := s.wishlistRepo.DeleteItem(itemID)
+	if err != nil {
+		return fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+
+	return nil
+}
+
+func validateWishlistDates(checkIn, checkOut *time.Time) error {
+	if checkIn != nil && checkOut != nil && !checkOut.After(*checkIn) {
+		return errors.New("check-out date must be after check-in date")
+	}
+	return nil
+}
+
+func (s *WishlistService) AddCollaborator(wishlistID, userID uuid.UUID, req *models.WishlistCollaboratorRequest) (*models.WishlistResponse, error) {
+	wishlist, err := s.getOwnWishlist(wishlistID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.ID == wishlist.OwnerID {
+		return nil, errors.New("the owner cannot be a collaborator")
+	}
+	if isWishlistMember(wishlist, user.ID) {
+		return nil, errors.New("user is already a collaborator")
+	}
+
+	collaborator := models.WishlistCollaborator{
+		WishlistID: wishlistID,
+		UserID:     user.ID,
+	}
+	err = s.wishlistRepo.AddCollaborator(&collaborator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add collaborator: %w", err)
+	}
+
+	collaborator.User = *user
+	wishlist.Collaborators = append(wishlist.Collaborators, collaborator)
+	return wishlist.ToResponse(true), nil
+}
+
+// removes a collaborator. The owner can remove anyone and collaborators can
+// remove themselves to leave the wishlist.
+func (s *WishlistService) RemoveCollaborator(wishlistID, collaboratorID, userID uuid.UUID) error {
+	wishlist, err := s.getMemberWishlist(wishlistID, userID)
+	if err != nil {
+		return err
+	}
+
+	if wishlist.OwnerID != userID && collaboratorID != userID {
+		return errors.New("unauthorized: only the wishlist owner can do this")
+	}
+
+	found := false
+	for _, collaborator := range wishlist.Collaborators {
+		if collaborator.UserID == collaboratorID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("collaborator not found")
+	}
+
+	err = s.wishlistRepo.RemoveCollaborator(wishlistID, collaboratorID)
+	if err != nil {
+		return fmt.Errorf("failed to remove collaborator: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFavorites returns copies of the properties with IsFavorited set for the
+// user. The inputs are left untouched since they may be shared cache entries.
+func (s *WishlistService) MarkFavorites(userID uuid.UUID, properties []*models.PropertyResponse) ([]*models.PropertyResponse, error) {
+	propertyIDs := make([]uuid.UUID, len(properties))
+	for i, property := range properties {
+		propertyIDs[i] = property.ID
+	}
+
+	favoritedIDs, err := s.wishlistRepo.GetFavoritedPropertyIDs(userID, propertyIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get favorited properties: %w", err)
+	}
+
+	favorited := make(map[uuid.UUID]bool, len(favoritedIDs))
+	for _, id := range favoritedIDs {
+		favorited[id] = true
+	}
+
+	marked := make([]*models.PropertyResponse, len(properties))
+	for i, property := range properties {
+		copied := *property
+		isFavorited := favorited[property.ID]
+		copied.IsFavorited = &isFavorited
+		marked[i] = &copied
+	}
+
+	return marked, nil
+}
@@ -0,0 +1,60 @@
+// Package storage keeps uploaded files in an object store, either on the
+// local filesystem or in an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"airbnb-clone/internal/config"
+)
+
+// ObjectStore stores objects under slash separated keys such as
+// "properties/<id>/<name>.jpg" and serves them at public URLs
+type ObjectStore interface {
+	// Put stores data under key, replacing any object already there
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete removes the object under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of the object under key
+	URL(key string) string
+}
+
+// creates the object store selected by the configuration
+func New(cfg config.StorageConfig) (ObjectStore, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStore(cfg.LocalDir, cfg.PublicBaseURL)
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:      cfg.S3Endpoint,
+			Region:        cfg.S3Region,
+			Bucket:        cfg.S3Bucket,
+			AccessKey:     cfg.S3AccessKey,
+			SecretKey:     cfg.S3SecretKey,
+			PathStyle:     cfg.S3PathStyle,
+			PublicBaseURL: cfg.PublicBaseURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// joins a base URL and a key without doubling the slash between them
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
+
+// rejects keys that could escape the store, such as "../secrets"
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return nil
+}